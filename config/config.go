@@ -3,6 +3,11 @@ package config
 import (
 	"fmt"
 	"log/slog"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
 
 	cfgPkg "github.com/eclipse-xfsc/microservice-core-go/pkg/config"
@@ -14,19 +19,36 @@ import (
 const (
 	ImporterGit       = "GIT"
 	ImporterBroadcast = "BROADCAST"
+	ImporterFile      = "FILE"
+	ImporterMirror    = "MIRROR"
+	ImporterComposite = "COMPOSITE"
 )
 
+var fullCommitHash = regexp.MustCompile(`^[0-9a-fA-F]{40}$`)
+
 type Config struct {
 	cfgPkg.BaseConfig `envconfig:"CORE"`
 
 	Postgres                          postgresPkg.Config            `envconfig:"POSTGRES"`
 	Nats                              cloudeventprovider.NatsConfig `envconfig:"NATS"`
 	Git                               GitConfig                     `envconfig:"GIT"`
+	File                              FileConfig                    `envconfig:"FILE"`
+	Mirror                            MirrorConfig                  `envconfig:"MIRROR"`
+	Composite                         CompositeConfig               `envconfig:"COMPOSITE"`
 	CredentialIssuer                  CredentialIssuerConfig        `envconfig:"CREDENTIAL_ISSUER"`
 	Gateway                           GatewayConfig                 `envconfig:"GATEWAY"`
+	Signing                           SigningConfig                 `envconfig:"SIGNING"`
+	Admin                             AdminConfig                   `envconfig:"ADMIN"`
+	Solicitation                      SolicitationConfig            `envconfig:"SOLICITATION"`
 	CredentialConfigurationExpiration int                           `envconfig:"CREDENTIAL_CONFIGURATION_EXPIRATION" default:"60"`
+	// CredentialConfigurationSweepInterval is the interval in which expired configurations are removed, 0 disables it
+	CredentialConfigurationSweepInterval time.Duration `envconfig:"CREDENTIAL_CONFIGURATION_SWEEP_INTERVAL" default:"1m"`
 }
 
+// GatewayConfig configures the REST gateway. PublicURL is the URL of the service in front of /v1/tenants, which
+// the templates, asset URLs and schema URIs are built with. Without it, the URL is taken from the request; the
+// X-Forwarded-Proto and X-Forwarded-Host headers are only honored from the addresses (or CIDR ranges) of
+// TrustedProxies.
 type GatewayConfig struct {
 	CredentialIssuerHeaderKey           string `envconfig:"CREDENTIAL_ISSUER_HEADER_KEY"`
 	AuthorizationServerHeaderKey        string `envconfig:"AUTHORIZATION_SERVER_HEADER_KEY"`
@@ -34,21 +56,229 @@ type GatewayConfig struct {
 	BatchCredentialEndpointHeaderKey    string `envconfig:"BATCH_CREDENTIAL_ENDPOINT_HEADER_KEY"`
 	DeferredCredentialEndpointHeaderKey string `envconfig:"DEFERRED_CREDENTIAL_ENDPOINT_HEADER_KEY"`
 	NotificationEndpointHeaderKey       string `envconfig:"NOTIFICATION_ENDPOINT_HEADER_KEY"`
+	AuthorizationEndpointHeaderKey      string `envconfig:"AUTHORIZATION_ENDPOINT_HEADER_KEY"`
+	TokenEndpointHeaderKey              string `envconfig:"TOKEN_ENDPOINT_HEADER_KEY"`
+	TypeMetadataSchemaByReference       bool   `envconfig:"TYPE_METADATA_SCHEMA_BY_REFERENCE" default:"false"`
+	LocalizeDisplay                     bool   `envconfig:"LOCALIZE_DISPLAY" default:"false"`
+	// CacheMaxAge is the max-age (seconds) of Cache-Control, CacheMaxAgeTenants overrides it per tenant
+	// (tenant1:60,tenant2:300). Without max-age, clients have to revalidate with ETag or Last-Modified.
+	CacheMaxAge        int            `envconfig:"CACHE_MAX_AGE" default:"0"`
+	CacheMaxAgeTenants map[string]int `envconfig:"CACHE_MAX_AGE_TENANTS"`
+
+	PublicURL      string   `envconfig:"PUBLIC_URL"`
+	TrustedProxies []string `envconfig:"TRUSTED_PROXIES"`
+}
+
+// SigningConfig configures the key which signs the metadata of a tenant. Keys are managed by the crypto
+// provider plugin; the tenant id is used as namespace of the crypto context.
+type SigningConfig struct {
+	Enabled    bool   `envconfig:"ENABLED" default:"false"`
+	PluginPath string `envconfig:"PLUGIN_PATH"`
+	Engine     string `envconfig:"ENGINE"`
+	Group      string `envconfig:"GROUP" default:"wellknown"`
+	KeyID      string `envconfig:"KEY_ID" default:"metadata"`
+	KeyType    string `envconfig:"KEY_TYPE" default:"ecdsa-p256"`
+	X5c        bool   `envconfig:"X5C" default:"false"`
+}
+
+// AdminConfig configures the admin API. Requests have to carry a bearer token which is verified against
+// the keys published at JwksURL and whose TenantClaim contains the tenant of the request.
+type AdminConfig struct {
+	Enabled             bool          `envconfig:"ENABLED" default:"false"`
+	JwksURL             string        `envconfig:"JWKS_URL"`
+	JwksRefreshInterval time.Duration `envconfig:"JWKS_REFRESH_INTERVAL" default:"15m"`
+	TenantClaim         string        `envconfig:"TENANT_CLAIM"`
+}
+
+// SolicitationConfig configures the broadcast importer to periodically ask the plugins of every known tenant
+// to announce their issuer and credential configurations again. Registrations which are not repeated within
+// Window are reported as missing and removed, if PruneMissing is set. An Interval of 0 disables it.
+type SolicitationConfig struct {
+	Interval     time.Duration `envconfig:"INTERVAL" default:"0"`
+	Window       time.Duration `envconfig:"WINDOW" default:"30s"`
+	PruneMissing bool          `envconfig:"PRUNE_MISSING" default:"false"`
 }
 
 type CredentialIssuerConfig struct {
 	Importer string `envconfig:"IMPORTER" required:"true" default:"BROADCAST"`
 }
 
+// GitConfig configures the git importer. Repo is an HTTP(S), SSH or file:// URL (or a local path, also of a
+// bare repository). At most one of Branch, Tag and Commit pins the checked out revision, otherwise the default
+// branch is used. HTTP(S) repositories authenticate with Username and Password or Token, SSH repositories with
+// the key at SSHKeyPath, verified against KnownHostsPath (default ~/.ssh/known_hosts).
+//
+// With SyncDatabase, every activated commit is stored in the database as well, so the NATS gateway serves the
+// same metadata. The templates of the stored metadata are rendered with PublicURL, the URL of the service in
+// front of /v1/tenants; tenants with templates are not stored without it. Only records stored by the sync are
+// removed or replaced.
+//
+// With WebhookSecret, push events of GitHub, GitLab and Gitea at WebhookPath trigger a pull after
+// WebhookDebounce. GitHub and Gitea sign the payload with the secret, GitLab sends it as token.
+//
+// With VerifySignatures, only commits signed by a key of the armored OpenPGP keyring at PGPKeyRingPath or of
+// the SSH keys at SSHAllowedSignersPath (allowed_signers format) are activated. These keys are
+// unrelated to SSHKeyPath, which authenticates the clone.
 type GitConfig struct {
-	ImagePath string        `envconfig:"IMAGE_PATH"`
-	Repo      string        `envconfig:"REPO"`
-	Token     string        `envconfig:"TOKEN"`
-	Interval  time.Duration `envconfig:"INTERVAL"`
+	Repo                  string        `envconfig:"REPO"`
+	Token                 string        `envconfig:"TOKEN"`
+	Interval              time.Duration `envconfig:"INTERVAL"`
+	Username              string        `envconfig:"USERNAME"`
+	Password              string        `envconfig:"PASSWORD"`
+	Branch                string        `envconfig:"BRANCH"`
+	Tag                   string        `envconfig:"TAG"`
+	Commit                string        `envconfig:"COMMIT"`
+	SSHUser               string        `envconfig:"SSH_USER" default:"git"`
+	SSHKeyPath            string        `envconfig:"SSH_KEY_PATH"`
+	SSHKeyPassphrase      string        `envconfig:"SSH_KEY_PASSPHRASE"`
+	KnownHostsPath        string        `envconfig:"KNOWN_HOSTS_PATH"`
+	SyncDatabase          bool          `envconfig:"SYNC_DATABASE" default:"true"`
+	PublicURL             string        `envconfig:"PUBLIC_URL"`
+	WebhookPath           string        `envconfig:"WEBHOOK_PATH" default:"/git/webhook"`
+	WebhookSecret         string        `envconfig:"WEBHOOK_SECRET"`
+	WebhookDebounce       time.Duration `envconfig:"WEBHOOK_DEBOUNCE" default:"5s"`
+	VerifySignatures      bool          `envconfig:"VERIFY_SIGNATURES" default:"false"`
+	PGPKeyRingPath        string        `envconfig:"PGP_KEYRING_PATH"`
+	SSHAllowedSignersPath string        `envconfig:"SSH_ALLOWED_SIGNERS_PATH"`
+	// Deprecated: ImagePath redirects the URLs of the former image route to the assets for one release
+	ImagePath string `envconfig:"IMAGE_PATH"`
+}
+
+// FileConfig configures the file importer, which serves the layout of the git repository from a local directory
+type FileConfig struct {
+	Path string `envconfig:"PATH"`
+	// Deprecated: ImagePath redirects the URLs of the former image route to the assets for one release
+	ImagePath string `envconfig:"IMAGE_PATH"`
+}
+
+// MirrorConfig configures the mirror importer, which re-exposes the metadata of upstream credential issuers.
+// Upstreams maps tenants to the credential issuer identifier of their upstream (tenant=https://issuer.example.org),
+// RewriteHosts replaces hosts in the endpoints of the upstream metadata (issuer.example.org=wellknown.example.com).
+type MirrorConfig struct {
+	Upstreams    KeyValues     `envconfig:"UPSTREAMS"`
+	RewriteHosts KeyValues     `envconfig:"REWRITE_HOSTS"`
+	Interval     time.Duration `envconfig:"INTERVAL" default:"5m"`
+	Timeout      time.Duration `envconfig:"TIMEOUT" default:"10s"`
+}
+
+// CompositeConfig configures the composite importer. Importers lists the chained importers in their default
+// precedence. IssuerPrecedence and ConfigurationsPrecedence override the precedence per tenant for the issuer
+// metadata and the credential configurations respectively (tenant-a=BROADCAST|GIT).
+type CompositeConfig struct {
+	Importers                []string  `envconfig:"IMPORTERS" default:"GIT,BROADCAST"`
+	IssuerPrecedence         KeyValues `envconfig:"ISSUER_PRECEDENCE"`
+	ConfigurationsPrecedence KeyValues `envconfig:"CONFIGURATIONS_PRECEDENCE"`
+}
+
+// Precedence returns the importers of the tenant in the order given by overrides or the default precedence
+func (c CompositeConfig) Precedence(overrides KeyValues, tenantID string) []string {
+	if value, ok := overrides[tenantID]; ok {
+		return strings.Split(value, "|")
+	}
+
+	return c.Importers
+}
+
+func (c CompositeConfig) validate() error {
+	if len(c.Importers) == 0 {
+		return fmt.Errorf("%s_COMPOSITE_IMPORTERS must not be empty", EnvPrefix)
+	}
+
+	// both importers own the issuer records of the database
+	if slices.Contains(c.Importers, ImporterBroadcast) && slices.Contains(c.Importers, ImporterMirror) {
+		return fmt.Errorf("the composite importer can not combine %s and %s", ImporterBroadcast, ImporterMirror)
+	}
+
+	for i, importer := range c.Importers {
+		switch importer {
+		case ImporterGit, ImporterFile, ImporterMirror, ImporterBroadcast:
+		default:
+			return fmt.Errorf("unsupported importer in the composite importer: %s", importer)
+		}
+
+		if slices.Contains(c.Importers[:i], importer) {
+			return fmt.Errorf("importer %s is listed twice in %s_COMPOSITE_IMPORTERS", importer, EnvPrefix)
+		}
+	}
+
+	for _, overrides := range []KeyValues{c.IssuerPrecedence, c.ConfigurationsPrecedence} {
+		for tenantID := range overrides {
+			precedence := c.Precedence(overrides, tenantID)
+			for i, importer := range precedence {
+				if !slices.Contains(c.Importers, importer) {
+					return fmt.Errorf("precedence of tenant %s refers to unknown importer %s", tenantID, importer)
+				}
+
+				if slices.Contains(precedence[:i], importer) {
+					return fmt.Errorf("precedence of tenant %s lists importer %s twice", tenantID, importer)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// KeyValues is a map which is decoded from comma separated key=value pairs. Unlike maps decoded by envconfig,
+// the values may contain colons, e.g. URLs.
+type KeyValues map[string]string
+
+func (kv *KeyValues) Decode(value string) error {
+	values := make(KeyValues)
+
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok || k == "" {
+			return fmt.Errorf("invalid key=value pair: %q", pair)
+		}
+
+		values[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+
+	*kv = values
+
+	return nil
 }
 
 const EnvPrefix = "WELLKNOWN_SERVICE"
 
+// GitSyncsDatabase reports whether the git importer stores its metadata in the database. It is disabled next
+// to the importers which own the database.
+func (c *Config) GitSyncsDatabase() bool {
+	importers := c.Importers()
+
+	return c.Git.SyncDatabase && slices.Contains(importers, ImporterGit) &&
+		!slices.Contains(importers, ImporterBroadcast) && !slices.Contains(importers, ImporterMirror)
+}
+
+// LegacyImagePaths returns the deprecated image routes of the git and file importers in use
+func (c *Config) LegacyImagePaths() []string {
+	var paths []string
+
+	importers := c.Importers()
+	if slices.Contains(importers, ImporterGit) && c.Git.ImagePath != "" {
+		paths = append(paths, c.Git.ImagePath)
+	}
+	if slices.Contains(importers, ImporterFile) && c.File.ImagePath != "" && !slices.Contains(paths, c.File.ImagePath) {
+		paths = append(paths, c.File.ImagePath)
+	}
+
+	return paths
+}
+
+// Importers returns the importers in use, which are more than one for the composite importer
+func (c *Config) Importers() []string {
+	if c.CredentialIssuer.Importer == ImporterComposite {
+		return c.Composite.Importers
+	}
+
+	return []string{c.CredentialIssuer.Importer}
+}
+
 func (c *Config) Validate() error {
 	var missing []string
 
@@ -61,19 +291,128 @@ func (c *Config) Validate() error {
 		}
 	}
 
-	if c.CredentialIssuer.Importer == ImporterGit {
+	importers := c.Importers()
+
+	if slices.Contains(importers, ImporterGit) {
 		check(c.Git.Repo, "GIT_REPO")
-		check(c.Git.Token, "GIT_TOKEN")
-		check(c.Git.ImagePath, "GIT_IMAGE_PATH")
 
 		if c.Git.Interval == 0 {
 			check("", "GIT_INTERVAL")
 		}
 	}
 
+	if slices.Contains(importers, ImporterFile) {
+		check(c.File.Path, "FILE_PATH")
+	}
+
+	if slices.Contains(importers, ImporterMirror) && len(c.Mirror.Upstreams) == 0 {
+		check("", "MIRROR_UPSTREAMS")
+	}
+
+	if c.Admin.Enabled {
+		check(c.Admin.JwksURL, "ADMIN_JWKS_URL")
+		// the JWKS only proves the token was issued by the identity provider, not for which tenant
+		check(c.Admin.TenantClaim, "ADMIN_TENANT_CLAIM")
+	}
+
 	if len(missing) > 0 {
 		return fmt.Errorf("missing required environment variables: %v", missing)
 	}
 
+	if slices.Contains(importers, ImporterGit) {
+		pins := 0
+		for _, pin := range []string{c.Git.Branch, c.Git.Tag, c.Git.Commit} {
+			if pin != "" {
+				pins++
+			}
+		}
+
+		if pins > 1 {
+			return fmt.Errorf("only one of %[1]s_GIT_BRANCH, %[1]s_GIT_TAG and %[1]s_GIT_COMMIT can be set", EnvPrefix)
+		}
+
+		// short hashes would be zero padded to a different commit
+		if c.Git.Commit != "" && !fullCommitHash.MatchString(c.Git.Commit) {
+			return fmt.Errorf("%s_GIT_COMMIT has to be the full hash of 40 hex digits", EnvPrefix)
+		}
+
+		if c.Git.PublicURL != "" {
+			if u, err := url.Parse(c.Git.PublicURL); err != nil || !u.IsAbs() || u.Host == "" {
+				return fmt.Errorf("%s_GIT_PUBLIC_URL has to be an absolute URL", EnvPrefix)
+			}
+		}
+
+		if c.Git.VerifySignatures && c.Git.PGPKeyRingPath == "" && c.Git.SSHAllowedSignersPath == "" {
+			return fmt.Errorf("%[1]s_GIT_VERIFY_SIGNATURES requires %[1]s_GIT_PGP_KEYRING_PATH or %[1]s_GIT_SSH_ALLOWED_SIGNERS_PATH", EnvPrefix)
+		}
+	}
+
+	if c.Admin.Enabled {
+		// the admin API writes to the database, which only the broadcast and mirror importers serve
+		if !slices.Contains(importers, ImporterBroadcast) && !slices.Contains(importers, ImporterMirror) {
+			return fmt.Errorf("%s_ADMIN_ENABLED requires the %s or %s importer", EnvPrefix, ImporterBroadcast, ImporterMirror)
+		}
+	}
+
+	for _, imagePath := range c.LegacyImagePaths() {
+		slog.Warn("image path is deprecated and only redirects to the assets, it will be removed with the next release",
+			"path", imagePath)
+	}
+
+	if err := c.Gateway.validate(); err != nil {
+		return err
+	}
+
+	// metadata signed with the origin of a request could be replayed by anyone sending a forged Host
+	if c.Signing.Enabled && c.Gateway.PublicURL == "" {
+		return fmt.Errorf("%[1]s_SIGNING_ENABLED requires %[1]s_GATEWAY_PUBLIC_URL", EnvPrefix)
+	}
+
+	if c.CredentialIssuer.Importer == ImporterComposite {
+		return c.Composite.validate()
+	}
+
+	return nil
+}
+
+func (g GatewayConfig) validate() error {
+	if g.PublicURL != "" {
+		u, err := url.Parse(g.PublicURL)
+		if err != nil || !u.IsAbs() || u.Host == "" || u.RawQuery != "" || u.Fragment != "" {
+			return fmt.Errorf("%s_GATEWAY_PUBLIC_URL has to be an absolute URL without query", EnvPrefix)
+		}
+	}
+
+	if _, err := g.TrustedProxyPrefixes(); err != nil {
+		return fmt.Errorf("invalid %s_GATEWAY_TRUSTED_PROXIES: %w", EnvPrefix, err)
+	}
+
 	return nil
 }
+
+// TrustedProxyPrefixes parses TrustedProxies, which are IP addresses or CIDR ranges
+func (g GatewayConfig) TrustedProxyPrefixes() ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(g.TrustedProxies))
+	for _, proxy := range g.TrustedProxies {
+		proxy = strings.TrimSpace(proxy)
+
+		if !strings.Contains(proxy, "/") {
+			addr, err := netip.ParseAddr(proxy)
+			if err != nil {
+				return nil, err
+			}
+
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			return nil, err
+		}
+
+		prefixes = append(prefixes, prefix.Masked())
+	}
+
+	return prefixes, nil
+}
@@ -0,0 +1,164 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestKeyValuesDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    KeyValues
+		wantErr bool
+	}{
+		{name: "empty", value: "", want: KeyValues{}},
+		{name: "pairs", value: "a=1,b=2", want: KeyValues{"a": "1", "b": "2"}},
+		{name: "urls", value: "tenant-a=https://issuer.example:8443/a?x=y", want: KeyValues{"tenant-a": "https://issuer.example:8443/a?x=y"}},
+		{name: "spaces", value: " a = 1 , b=2 ", want: KeyValues{"a": "1", "b": "2"}},
+		{name: "empty pairs", value: "a=1,,", want: KeyValues{"a": "1"}},
+		{name: "empty value", value: "a=", want: KeyValues{"a": ""}},
+		{name: "value with equals", value: "a=b=c", want: KeyValues{"a": "b=c"}},
+		{name: "precedence", value: "tenant-a=BROADCAST|GIT", want: KeyValues{"tenant-a": "BROADCAST|GIT"}},
+		{name: "missing equals", value: "a=1,b", wantErr: true},
+		{name: "missing key", value: "=1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var kv KeyValues
+			err := kv.Decode(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+
+			if !tt.wantErr && !reflect.DeepEqual(kv, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, kv)
+			}
+		})
+	}
+}
+
+func TestCompositeConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		conf    CompositeConfig
+		wantErr bool
+	}{
+		{name: "valid", conf: CompositeConfig{Importers: []string{ImporterGit, ImporterBroadcast}}},
+		{name: "empty", conf: CompositeConfig{}, wantErr: true},
+		{name: "unknown importer", conf: CompositeConfig{Importers: []string{ImporterGit, "S3"}}, wantErr: true},
+		{name: "duplicate importer", conf: CompositeConfig{Importers: []string{ImporterGit, ImporterBroadcast, ImporterGit}}, wantErr: true},
+		{name: "broadcast and mirror", conf: CompositeConfig{Importers: []string{ImporterBroadcast, ImporterMirror}}, wantErr: true},
+		{
+			name: "valid precedence",
+			conf: CompositeConfig{
+				Importers:        []string{ImporterGit, ImporterBroadcast},
+				IssuerPrecedence: KeyValues{"tenant": "BROADCAST|GIT"},
+			},
+		},
+		{
+			name: "precedence with unknown importer",
+			conf: CompositeConfig{
+				Importers:                []string{ImporterGit, ImporterBroadcast},
+				ConfigurationsPrecedence: KeyValues{"tenant": "FILE|GIT"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "precedence with duplicate importer",
+			conf: CompositeConfig{
+				Importers:        []string{ImporterGit, ImporterBroadcast},
+				IssuerPrecedence: KeyValues{"tenant": "GIT|GIT"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.conf.validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestGatewayConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		conf    GatewayConfig
+		wantErr bool
+	}{
+		{name: "empty", conf: GatewayConfig{}},
+		{name: "public url", conf: GatewayConfig{PublicURL: "https://issuer.example/wellknown"}},
+		{name: "relative public url", conf: GatewayConfig{PublicURL: "/wellknown"}, wantErr: true},
+		{name: "public url with query", conf: GatewayConfig{PublicURL: "https://issuer.example?a=b"}, wantErr: true},
+		{name: "trusted proxies", conf: GatewayConfig{TrustedProxies: []string{"10.0.0.1", "10.1.0.0/16", "::1", "fd00::/8"}}},
+		{name: "invalid trusted proxy", conf: GatewayConfig{TrustedProxies: []string{"proxy.example"}}, wantErr: true},
+		{name: "invalid trusted range", conf: GatewayConfig{TrustedProxies: []string{"10.0.0.0/33"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.conf.validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestGitCommitValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		commit  string
+		wantErr bool
+	}{
+		{name: "none", commit: ""},
+		{name: "full hash", commit: "0123456789abcdef0123456789ABCDEF01234567"},
+		{name: "short hash", commit: "0123456", wantErr: true},
+		{name: "too long", commit: "0123456789abcdef0123456789abcdef012345678", wantErr: true},
+		{name: "not hex", commit: "0123456789abcdef0123456789abcdef0123456g", wantErr: true},
+		{name: "branch name", commit: "main", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := Config{
+				CredentialIssuer: CredentialIssuerConfig{Importer: ImporterGit},
+				Git:              GitConfig{Repo: "https://git.example/repo.git", Interval: time.Minute, Commit: tt.commit},
+			}
+
+			if err := conf.Validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestAdminConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		admin   AdminConfig
+		wantErr bool
+	}{
+		{name: "disabled", admin: AdminConfig{}},
+		{name: "enabled", admin: AdminConfig{Enabled: true, JwksURL: "https://idp.example/jwks", TenantClaim: "tenants"}},
+		{name: "without tenant claim", admin: AdminConfig{Enabled: true, JwksURL: "https://idp.example/jwks"}, wantErr: true},
+		{name: "without jwks", admin: AdminConfig{Enabled: true, TenantClaim: "tenants"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := Config{
+				CredentialIssuer: CredentialIssuerConfig{Importer: ImporterBroadcast},
+				Admin:            tt.admin,
+			}
+
+			if err := conf.Validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
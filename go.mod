@@ -8,6 +8,12 @@ require (
 	github.com/Masterminds/squirrel v1.5.4
 	github.com/cloudevents/sdk-go/v2 v2.15.2
 	github.com/eapache/go-resiliency v1.6.0
+	github.com/eclipse-xfsc/cloud-event-provider v0.1.5
+	github.com/eclipse-xfsc/crypto-provider-core v1.4.1
+	github.com/eclipse-xfsc/microservice-core-go v1.1.1
+	github.com/eclipse-xfsc/nats-message-library v1.1.14
+	github.com/eclipse-xfsc/oid4-vci-vp-library v1.4.6
+	github.com/fsnotify/fsnotify v1.9.0
 	github.com/gin-gonic/gin v1.10.0
 	github.com/google/uuid v1.6.0
 	github.com/jackc/pgx/v5 v5.7.4
@@ -15,8 +21,12 @@ require (
 	github.com/madflojo/tasks v1.2.1
 	github.com/swaggo/gin-swagger v1.6.0
 	github.com/swaggo/swag v1.16.4
+	golang.org/x/crypto v0.37.0
 	golang.org/x/sync v0.13.0
+	golang.org/x/text v0.24.0
+	gopkg.in/src-d/go-billy.v4 v4.3.2
 	gopkg.in/src-d/go-git.v4 v4.13.1
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -41,16 +51,10 @@ require (
 	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.4.0 // indirect
 	github.com/eapache/go-xerial-snappy v0.0.0-20230731223053-c322873962e3 // indirect
 	github.com/eapache/queue v1.1.0 // indirect
-	github.com/eclipse-xfsc/cloud-event-provider v0.1.5 // indirect
-	github.com/eclipse-xfsc/crypto-provider-core v1.4.1 // indirect
 	github.com/eclipse-xfsc/did-core v1.0.2 // indirect
-	github.com/eclipse-xfsc/microservice-core-go v1.1.1 // indirect
-	github.com/eclipse-xfsc/nats-message-library v1.1.14 // indirect
-	github.com/eclipse-xfsc/oid4-vci-vp-library v1.4.6 // indirect
 	github.com/eclipse-xfsc/ssi-jwt v1.2.1 // indirect
 	github.com/eclipse/paho.golang v0.12.0 // indirect
 	github.com/emirpasic/gods v1.18.1 // indirect
-	github.com/fsnotify/fsnotify v1.9.0 // indirect
 	github.com/gabriel-vasile/mimetype v1.4.9 // indirect
 	github.com/gin-contrib/sse v1.1.0 // indirect
 	github.com/go-logr/logr v1.4.3 // indirect
@@ -130,17 +134,13 @@ require (
 	go.uber.org/multierr v1.11.0 // indirect
 	go.uber.org/zap v1.27.0 // indirect
 	golang.org/x/arch v0.16.0 // indirect
-	golang.org/x/crypto v0.37.0 // indirect
 	golang.org/x/exp v0.0.0-20250408133849-7e4ce0ab07d0 // indirect
 	golang.org/x/mod v0.24.0 // indirect
 	golang.org/x/net v0.39.0 // indirect
 	golang.org/x/sys v0.32.0 // indirect
-	golang.org/x/text v0.24.0 // indirect
 	golang.org/x/tools v0.32.0 // indirect
 	google.golang.org/protobuf v1.36.6 // indirect
 	gopkg.in/ini.v1 v1.67.0 // indirect
-	gopkg.in/src-d/go-billy.v4 v4.3.2 // indirect
 	gopkg.in/warnings.v0 v0.1.2 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
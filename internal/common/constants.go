@@ -1,3 +1,6 @@
 package common
 
 const BasePath = "/.well-known"
+
+// AssetsPath serves the assets of a tenant (e.g. logos) next to BasePath
+const AssetsPath = "/assets"
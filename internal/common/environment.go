@@ -4,15 +4,16 @@ import (
 	"github.com/eclipse-xfsc/microservice-core-go/pkg/logr"
 
 	ginSwagger "github.com/swaggo/gin-swagger"
-	"github.com/swaggo/swag/example/basic/docs"
 
 	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/config"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/docs"
 )
 
 type Environment struct {
-	logger     *logr.Logger
-	config     *config.Config
-	healthFunc func() bool
+	logger        *logr.Logger
+	config        *config.Config
+	healthFunc    func() bool
+	healthDetails map[string]func() any
 }
 
 var env *Environment
@@ -33,6 +34,25 @@ func (e *Environment) SetHealthFunc(healthFunc func() bool) {
 	e.healthFunc = healthFunc
 }
 
+// AddHealthDetail registers a status which is reported under the given name in the health output.
+// Details have to be registered before the server is started.
+func (e *Environment) AddHealthDetail(name string, detail func() any) {
+	if e.healthDetails == nil {
+		e.healthDetails = make(map[string]func() any)
+	}
+
+	e.healthDetails[name] = detail
+}
+
+func (e *Environment) HealthDetails() map[string]any {
+	details := make(map[string]any, len(e.healthDetails))
+	for name, detail := range e.healthDetails {
+		details[name] = detail()
+	}
+
+	return details
+}
+
 func (e *Environment) SetConfig(config *config.Config) {
 	e.config = config
 }
@@ -51,7 +71,8 @@ func (e *Environment) GetLogger() *logr.Logger {
 
 // SetSwaggerBasePath sets the base path that will be used by swagger ui for requests url generation
 func (e *Environment) SetSwaggerBasePath(path string) {
-	docs.SwaggerInfo.BasePath = path + BasePath
+	// documented routes (admin API) are relative to the tenant group, not to the well-known paths
+	docs.SwaggerInfo.BasePath = path
 }
 
 // SwaggerOptions swagger config options. See github.com/swaggo/gin-swagger?tab=readme-ov-file#configuration
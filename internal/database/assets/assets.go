@@ -0,0 +1,19 @@
+package assets
+
+import (
+	"context"
+	"time"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/types"
+)
+
+type Store interface {
+	GetAssetRecord(ctx context.Context, tenantID, name string) (*types.Asset, error)
+	// ListAssetRecords returns the assets of the tenant without their content
+	ListAssetRecords(ctx context.Context, tenantID string) ([]types.Asset, error)
+	UpsertAssetRecord(ctx context.Context, tenantID string, asset types.Asset) error
+	// DeleteAssetRecord removes the asset and records the deletion time of the tenant
+	DeleteAssetRecord(ctx context.Context, tenantID, name string) error
+	// GetAssetsLastModified returns the latest update or deletion of an asset of the tenant, zero without any
+	GetAssetsLastModified(ctx context.Context, tenantID string) (time.Time, error)
+}
@@ -0,0 +1,210 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/logr"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/database"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/database/assets"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/database/postgres"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/types"
+)
+
+type Store struct {
+	log logr.Logger
+	db  *pgxpool.Pool
+	sq  squirrel.StatementBuilderType
+}
+
+var _ assets.Store = Store{}
+
+const (
+	colTenantId    = "tenant_id"
+	colName        = "name"
+	colContentType = "content_type"
+	colHash        = "hash"
+	colContent     = "content"
+	colUpdatedAt   = "updated_at"
+	colDeletedAt   = "deleted_at"
+)
+
+func NewStore(db *pgxpool.Pool, logger logr.Logger) Store {
+	return Store{
+		log: logger,
+		db:  db,
+		sq:  postgres.StmtBuilderDollar(),
+	}
+}
+
+func (s Store) GetAssetRecord(ctx context.Context, tenantID, name string) (*types.Asset, error) {
+	query := s.sq.
+		Select(colName, colContentType, colHash, colContent, colUpdatedAt).
+		From(postgres.TblAssets).
+		Where(squirrel.Eq{colTenantId: tenantID, colName: name})
+
+	sql, params, err := query.ToSql()
+	if err != nil {
+		return nil, database.NewError("failed to build query", err)
+	}
+
+	var asset types.Asset
+	err = s.db.QueryRow(ctx, sql, params...).Scan(
+		&asset.Name, &asset.ContentType, &asset.Hash, &asset.Content, &asset.ModTime,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, database.ErrNotFound
+		}
+
+		s.log.Error(err, "failed to scan")
+		return nil, database.NewError("failed to execute query", err)
+	}
+	asset.Size = len(asset.Content)
+
+	return &asset, nil
+}
+
+// ListAssetRecords returns the assets of the tenant sorted by name. The content is not selected.
+func (s Store) ListAssetRecords(ctx context.Context, tenantID string) ([]types.Asset, error) {
+	query := s.sq.
+		Select(colName, colContentType, colHash, fmt.Sprintf("octet_length(%s)", colContent), colUpdatedAt).
+		From(postgres.TblAssets).
+		Where(squirrel.Eq{colTenantId: tenantID}).
+		OrderBy(colName)
+
+	sql, params, err := query.ToSql()
+	if err != nil {
+		return nil, database.NewError("failed to build query", err)
+	}
+
+	rows, err := s.db.Query(ctx, sql, params...)
+	if err != nil {
+		return nil, database.NewError("failed to execute query", err)
+	}
+	defer rows.Close()
+
+	out := make([]types.Asset, 0)
+	for rows.Next() {
+		var asset types.Asset
+		if err := rows.Scan(&asset.Name, &asset.ContentType, &asset.Hash, &asset.Size, &asset.ModTime); err != nil {
+			s.log.Error(err, "failed to scan")
+			return nil, database.NewError("failed to execute query", err)
+		}
+
+		out = append(out, asset)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, database.NewError("failed to execute query", err)
+	}
+
+	return out, nil
+}
+
+// UpsertAssetRecord inserts the asset or replaces the asset of the tenant with the same name
+func (s Store) UpsertAssetRecord(ctx context.Context, tenantID string, asset types.Asset) error {
+	query := s.sq.
+		Insert(postgres.TblAssets).
+		Columns(colTenantId, colName, colContentType, colHash, colContent, colUpdatedAt).
+		Values(tenantID, asset.Name, asset.ContentType, asset.Hash, asset.Content, asset.ModTime).
+		Suffix(fmt.Sprintf(
+			"ON CONFLICT (%s, %s) DO UPDATE SET %s = EXCLUDED.%s, %s = EXCLUDED.%s, %s = EXCLUDED.%s, %s = EXCLUDED.%s",
+			colTenantId, colName, colContentType, colContentType, colHash, colHash,
+			colContent, colContent, colUpdatedAt, colUpdatedAt,
+		))
+
+	sql, params, err := query.ToSql()
+	if err != nil {
+		return database.NewError("failed to build query", err)
+	}
+
+	if _, err := s.db.Exec(ctx, sql, params...); err != nil {
+		return database.NewError("failed to execute query", err)
+	}
+
+	return nil
+}
+
+// DeleteAssetRecord removes the asset of the tenant. The deletion time is recorded, as the remaining assets
+// don't reflect the deletion.
+func (s Store) DeleteAssetRecord(ctx context.Context, tenantID, name string) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return database.NewError("failed to begin transaction", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	sql, params, err := s.sq.
+		Delete(postgres.TblAssets).
+		Where(squirrel.Eq{colTenantId: tenantID, colName: name}).
+		ToSql()
+	if err != nil {
+		return database.NewError("failed to build query", err)
+	}
+
+	tag, err := tx.Exec(ctx, sql, params...)
+	if err != nil {
+		return database.NewError("failed to execute query", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return database.ErrNotFound
+	}
+
+	sql, params, err = s.sq.
+		Insert(postgres.TblAssetDeletions).
+		Columns(colTenantId, colDeletedAt).
+		Values(tenantID, time.Now()).
+		Suffix(fmt.Sprintf("ON CONFLICT (%s) DO UPDATE SET %s = EXCLUDED.%s", colTenantId, colDeletedAt, colDeletedAt)).
+		ToSql()
+	if err != nil {
+		return database.NewError("failed to build query", err)
+	}
+
+	if _, err := tx.Exec(ctx, sql, params...); err != nil {
+		return database.NewError("failed to execute query", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return database.NewError("failed to commit transaction", err)
+	}
+
+	return nil
+}
+
+// GetAssetsLastModified returns the latest updated_at of the assets of the tenant or its last deletion of an
+// asset. It is zero for tenants without any.
+func (s Store) GetAssetsLastModified(ctx context.Context, tenantID string) (time.Time, error) {
+	sql, params, err := s.sq.
+		Select().
+		Column(squirrel.Expr(fmt.Sprintf(
+			"GREATEST((SELECT MAX(%s) FROM %s WHERE %s = ?), (SELECT %s FROM %s WHERE %s = ?))",
+			colUpdatedAt, postgres.TblAssets, colTenantId,
+			colDeletedAt, postgres.TblAssetDeletions, colTenantId,
+		), tenantID, tenantID)).
+		ToSql()
+	if err != nil {
+		return time.Time{}, database.NewError("failed to build query", err)
+	}
+
+	var lastModified *time.Time
+	if err := s.db.QueryRow(ctx, sql, params...).Scan(&lastModified); err != nil {
+		return time.Time{}, database.NewError("failed to execute query", err)
+	}
+
+	if lastModified == nil {
+		return time.Time{}, nil
+	}
+
+	return *lastModified, nil
+}
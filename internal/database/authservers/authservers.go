@@ -0,0 +1,21 @@
+package authservers
+
+import (
+	"context"
+	"time"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/types"
+)
+
+type Store interface {
+	GetAuthorizationServerRecord(ctx context.Context, tenantID string) (*AuthorizationServer, error)
+	UpsertAuthorizationServerRecord(ctx context.Context, authorizationServer AuthorizationServer) error
+	DeleteAuthorizationServerRecord(ctx context.Context, tenantID string) error
+}
+
+type AuthorizationServer struct {
+	TenantID  string
+	Metadata  types.AuthorizationServerMetadata
+	FirstSeen time.Time
+	LastSeen  time.Time
+}
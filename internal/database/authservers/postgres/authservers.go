@@ -0,0 +1,115 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/logr"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/database"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/database/authservers"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/database/postgres"
+)
+
+type Store struct {
+	log logr.Logger
+	db  *pgxpool.Pool
+	sq  squirrel.StatementBuilderType
+}
+
+var _ authservers.Store = Store{}
+
+const (
+	colTenantId  = "tenant_id"
+	colMetadata  = "metadata"
+	colFirstSeen = "first_seen"
+	colLastSeen  = "last_seen"
+)
+
+func NewStore(db *pgxpool.Pool, logger logr.Logger) Store {
+	return Store{
+		log: logger,
+		db:  db,
+		sq:  postgres.StmtBuilderDollar(),
+	}
+}
+
+func (s Store) GetAuthorizationServerRecord(ctx context.Context, tenantID string) (*authservers.AuthorizationServer, error) {
+	query := s.sq.
+		Select(colTenantId, colMetadata, colFirstSeen, colLastSeen).
+		From(postgres.TblAuthorizationServers).
+		Where(squirrel.Eq{colTenantId: tenantID})
+
+	sql, params, err := query.ToSql()
+	if err != nil {
+		return nil, database.NewError("failed to build query", err)
+	}
+
+	var record authservers.AuthorizationServer
+	err = s.db.QueryRow(ctx, sql, params...).Scan(&record.TenantID, &record.Metadata, &record.FirstSeen, &record.LastSeen)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, database.ErrNotFound
+		}
+
+		s.log.Error(err, "failed to scan")
+		return nil, database.NewError("failed to execute query", err)
+	}
+
+	return &record, nil
+}
+
+// UpsertAuthorizationServerRecord inserts the given record or, if there is already one for the tenant,
+// replaces its metadata. The first_seen timestamp of an existing record is kept.
+func (s Store) UpsertAuthorizationServerRecord(ctx context.Context, authorizationServer authservers.AuthorizationServer) error {
+	query := s.sq.
+		Insert(postgres.TblAuthorizationServers).
+		Columns(colTenantId, colMetadata, colFirstSeen, colLastSeen).
+		Values(
+			authorizationServer.TenantID, authorizationServer.Metadata,
+			authorizationServer.FirstSeen, authorizationServer.LastSeen,
+		).
+		Suffix(fmt.Sprintf(
+			"ON CONFLICT (%s) DO UPDATE SET %s = EXCLUDED.%s, %s = EXCLUDED.%s",
+			colTenantId, colMetadata, colMetadata, colLastSeen, colLastSeen,
+		))
+
+	sql, params, err := query.ToSql()
+	if err != nil {
+		return database.NewError("failed to build query", err)
+	}
+
+	if _, err := s.db.Exec(ctx, sql, params...); err != nil {
+		return database.NewError("failed to execute query", err)
+	}
+
+	return nil
+}
+
+// DeleteAuthorizationServerRecord removes the record of the tenant
+func (s Store) DeleteAuthorizationServerRecord(ctx context.Context, tenantID string) error {
+	query := s.sq.
+		Delete(postgres.TblAuthorizationServers).
+		Where(squirrel.Eq{colTenantId: tenantID})
+
+	sql, params, err := query.ToSql()
+	if err != nil {
+		return database.NewError("failed to build query", err)
+	}
+
+	tag, err := s.db.Exec(ctx, sql, params...)
+	if err != nil {
+		return database.NewError("failed to execute query", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return database.ErrNotFound
+	}
+
+	return nil
+}
@@ -5,19 +5,41 @@ import (
 	"time"
 
 	"github.com/eclipse-xfsc/oid4-vci-vp-library/model/credential"
+	"github.com/eclipse-xfsc/oid4-vci-vp-library/model/oauth"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/types"
 )
 
 type Store interface {
 	GetIssuerRecord(ctx context.Context, tenantID string) (*Issuer, error)
 	GetConfigurationsRecord(ctx context.Context, tenantID string) ([]CredentialsSupported, error)
 	InsertIssuerRecord(ctx context.Context, issuer Issuer) error
-	UpdateIssuerRecord(ctx context.Context, tenantID, credentialIssuer string, update IssuerUpdate) error
+	ReplaceIssuerRecord(ctx context.Context, issuer Issuer) error
 	InsertConfigurationsSupported(ctx context.Context, tenantID string, cs []CredentialsSupported) error
 	UpdateConfigurationsSupported(ctx context.Context, tenantID string, update []CredentialsSupported) error
+	UpdateSignedMetadata(ctx context.Context, tenantID, signedMetadata, digest string) error
+	DeleteIssuerRecord(ctx context.Context, tenantID string, configurationSources []string) error
+	DeleteConfigurationRecord(ctx context.Context, tenantID, configurationID string) error
+	DeleteExpiredConfigurations(ctx context.Context, source string, before time.Time) ([]CredentialsSupported, error)
+	GetLastModified(ctx context.Context, tenantID string) (time.Time, error)
+	ListTenants(ctx context.Context) ([]string, error)
+	GetOpenIDConfigurationRecord(ctx context.Context, tenantID string) (*OpenIDConfiguration, error)
+	UpsertOpenIDConfigurationRecord(ctx context.Context, configuration OpenIDConfiguration) error
+	GetJwtVcIssuerRecord(ctx context.Context, tenantID string) (*JwtVcIssuer, error)
+	UpsertJwtVcIssuerRecord(ctx context.Context, jwtVcIssuer JwtVcIssuer) error
 	// List(ctx context.Context, tenantID string) ([]Issuer, error)
 	// ListAll(ctx context.Context) ([]Issuer, error)
 }
 
+// Sources of the records. Importers only replace or remove records of their own source, the admin API
+// manages the records of every source. Records written before sources were tracked have none.
+const (
+	SourceBroadcast = "broadcast"
+	SourceAdmin     = "admin"
+	SourceGit       = "git"
+	SourceMirror    = "mirror"
+)
+
 type Issuer struct {
 	TenantID                       string
 	CredentialIssuer               string
@@ -31,8 +53,26 @@ type Issuer struct {
 	FirstSeen                      time.Time
 	LastSeen                       time.Time
 	SignedMetadata                 *string
+	SignedMetadataDigest           *string
 	NotificationEndpoint           *string
 	CredentialIdentifiersSupported bool
+	Source                         string
+}
+
+// OpenIDConfiguration is the OpenID Connect discovery document published for the issuer of a tenant
+type OpenIDConfiguration struct {
+	TenantID      string
+	Configuration oauth.OpenIdConfiguration
+	FirstSeen     time.Time
+	LastSeen      time.Time
+}
+
+// JwtVcIssuer is the SD-JWT VC issuer metadata published for the issuer of a tenant
+type JwtVcIssuer struct {
+	TenantID  string
+	Metadata  types.JwtVcIssuerMetadata
+	FirstSeen time.Time
+	LastSeen  time.Time
 }
 
 type CredentialRespEnc struct {
@@ -43,20 +83,6 @@ type CredentialRespEnc struct {
 
 type Locale string
 
-type IssuerUpdate struct {
-	AuthorizationServers           []string
-	CredentialEndpoint             *string
-	BatchCredentialEndpoint        *string
-	DeferredCredentialEndpoint     *string
-	CredentialResponseEncryption   *CredentialRespEnc
-	CredentialIdentifiersSupported bool
-	Display                        []credential.LocalizedCredential
-	CredentialsSupported           []CredentialsSupported
-	LastSeen                       *time.Time
-	SignedMetadata                 *string
-	NotificationEndpoint           *string
-}
-
 type CredentialsSupported struct {
 	TenantID                               string
 	CredentialConfigurationID              string
@@ -74,6 +100,7 @@ type CredentialsSupported struct {
 	Order                                  []string
 	FirstSeen                              time.Time
 	LastSeen                               time.Time
+	Source                                 string
 }
 
 type CredentialSupportedRow struct {
@@ -93,6 +120,7 @@ type CredentialSupportedRow struct {
 	Order                                  []string
 	FirstSeen                              time.Time
 	LastSeen                               time.Time
+	Source                                 *string
 }
 
 type ProofTypesSupported map[string]credential.ProofType
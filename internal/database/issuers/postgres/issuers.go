@@ -2,12 +2,16 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/eclipse-xfsc/microservice-core-go/pkg/logr"
 
 	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/config"
@@ -38,6 +42,7 @@ const (
 	colFirstSeen                      = "first_seen"
 	colLastSeen                       = "last_seen"
 	colSignedMetaData                 = "signed_metadata"
+	colSignedMetaDataDigest           = "signed_metadata_digest"
 	colNotificationEndpoint           = "notification_endpoint"
 	colCredentialIdentifiersSupported = "credential_identifiers_supported"
 
@@ -53,8 +58,20 @@ const (
 	colVct                                  = "vct"
 	colClaims                               = "claims"
 	colOrder                                = "\"order\""
+	colSource                               = "source"
+	colModifiedAt                           = "modified_at"
 )
 
+// sourceIn matches the records of the given sources, an empty source matches records without source
+func sourceIn(sources []string) squirrel.Sqlizer {
+	condition := squirrel.Or{squirrel.Eq{colSource: sources}}
+	if slices.Contains(sources, "") {
+		condition = append(condition, squirrel.Eq{colSource: nil})
+	}
+
+	return condition
+}
+
 func NewStore(db *pgxpool.Pool, logger logr.Logger, config config.Config) Store {
 	return Store{
 		log: logger,
@@ -106,7 +123,7 @@ func (s Store) InsertIssuerRecord(ctx context.Context, issuer issuers.Issuer) er
 			colBatchCredentialEndpoint, colDeferredCredentialEndpoint,
 			colCredentialResponseEncryption, colDisplay,
 			colFirstSeen, colLastSeen, colSignedMetaData,
-			colNotificationEndpoint, colCredentialIdentifiersSupported,
+			colNotificationEndpoint, colCredentialIdentifiersSupported, colSource,
 		).
 		Values(
 			issuer.TenantID, issuer.CredentialIssuer,
@@ -115,6 +132,7 @@ func (s Store) InsertIssuerRecord(ctx context.Context, issuer issuers.Issuer) er
 			issuer.CredentialResponseEncryption,
 			issuer.Display, issuer.FirstSeen, issuer.LastSeen,
 			issuer.SignedMetadata, issuer.NotificationEndpoint, issuer.CredentialIdentifiersSupported,
+			nullable(issuer.Source),
 		)
 
 	sql, params, err := query.ToSql()
@@ -122,21 +140,50 @@ func (s Store) InsertIssuerRecord(ctx context.Context, issuer issuers.Issuer) er
 		return database.NewError("failed to build query", err)
 	}
 
-	if _, err := s.db.Exec(ctx, sql, params...); err != nil {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return database.NewError("failed to begin transaction", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if _, err := tx.Exec(ctx, sql, params...); err != nil {
 		return database.NewError("failed to execute query", err)
 	}
 
-	return s.InsertConfigurationsSupported(ctx, issuer.TenantID, issuer.CredentialsSupported)
+	if err := s.insertConfigurationsSupported(ctx, tx, issuer.TenantID, issuer.CredentialsSupported); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return database.NewError("failed to commit transaction", err)
+	}
+
+	return nil
 }
 
 func (s Store) InsertConfigurationsSupported(ctx context.Context, tenantID string, cs []issuers.CredentialsSupported) error {
+	return s.insertConfigurationsSupported(ctx, s.db, tenantID, cs)
+}
+
+// executor runs statements on the pool or within a transaction
+type executor interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
+func (s Store) insertConfigurationsSupported(ctx context.Context, db executor, tenantID string, cs []issuers.CredentialsSupported) error {
+	if len(cs) == 0 {
+		return nil
+	}
+
 	query := s.sq.
 		Insert(postgres.TblCredentialsSupported).
 		Columns(
 			colTenantId, colCredentialConfigurationID, colFormat, colScope,
 			colCryptographicBindingMethodsSupported, colSigningAlgValuesSupported,
 			colCredentialDefinition, colProofTypesSupported, colSchema, colSubject,
-			colFirstSeen, colLastSeen, colDisplay, colVct, colClaims, colOrder,
+			colFirstSeen, colLastSeen, colDisplay, colVct, colClaims, colOrder, colSource,
 		)
 
 	for _, supported := range cs {
@@ -145,6 +192,7 @@ func (s Store) InsertConfigurationsSupported(ctx context.Context, tenantID strin
 			supported.CryptographicBindingMethodsSupported, supported.CryptographicSigningAlgValuesSupported,
 			supported.CredentialDefinition, supported.ProofTypesSupported, supported.Schema, supported.Subject,
 			supported.FirstSeen, supported.LastSeen, supported.Display, supported.Vct, supported.Claims, supported.Order,
+			nullable(supported.Source),
 		)
 	}
 
@@ -153,7 +201,7 @@ func (s Store) InsertConfigurationsSupported(ctx context.Context, tenantID strin
 		return database.NewError("failed to build query", err)
 	}
 
-	if _, err := s.db.Exec(ctx, sql, params...); err != nil {
+	if _, err := db.Exec(ctx, sql, params...); err != nil {
 		s.log.Error(err, "failed to insert credentials supported")
 		return database.NewError("failed to insert credentials supported", err)
 	}
@@ -161,38 +209,86 @@ func (s Store) InsertConfigurationsSupported(ctx context.Context, tenantID strin
 	return nil
 }
 
-func (s Store) UpdateIssuerRecord(ctx context.Context, tenantID, issuer string, update issuers.IssuerUpdate) error {
+// ReplaceIssuerRecord overwrites all fields of the issuer of the tenant, so fields missing in the given issuer
+// are cleared. first_seen is kept. The given credential configurations are replaced as well, others are kept.
+// Readers see either the previous or the replaced issuer, never a partial replacement.
+func (s Store) ReplaceIssuerRecord(ctx context.Context, issuer issuers.Issuer) error {
 	query := s.sq.
 		Update(postgres.TblIssuers).
-		Where(squirrel.Eq{colCredentialIssuer: issuer}).
-		Where(squirrel.Eq{colTenantId: tenantID})
+		Set(colCredentialIssuer, issuer.CredentialIssuer).
+		Set(colAuthorizationServers, issuer.AuthorizationServers).
+		Set(colCredentialEndpoint, issuer.CredentialEndpoint).
+		Set(colBatchCredentialEndpoint, issuer.BatchCredentialEndpoint).
+		Set(colDeferredCredentialEndpoint, issuer.DeferredCredentialEndpoint).
+		Set(colNotificationEndpoint, issuer.NotificationEndpoint).
+		Set(colCredentialResponseEncryption, issuer.CredentialResponseEncryption).
+		Set(colDisplay, issuer.Display).
+		Set(colCredentialIdentifiersSupported, issuer.CredentialIdentifiersSupported).
+		Set(colLastSeen, issuer.LastSeen).
+		Set(colSignedMetaData, issuer.SignedMetadata).
+		Set(colSignedMetaDataDigest, issuer.SignedMetadataDigest).
+		Set(colSource, nullable(issuer.Source)).
+		Where(squirrel.Eq{colTenantId: issuer.TenantID})
 
-	if update.CredentialEndpoint != nil {
-		query = query.Set(colCredentialEndpoint, update.CredentialEndpoint)
+	sql, params, err := query.ToSql()
+	if err != nil {
+		return database.NewError("failed to build query", err)
 	}
 
-	if update.AuthorizationServers != nil {
-		query = query.Set(colAuthorizationServers, update.AuthorizationServers)
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return database.NewError("failed to begin transaction", err)
 	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
 
-	if update.LastSeen != nil {
-		query = query.Set(colLastSeen, update.LastSeen)
+	tag, err := tx.Exec(ctx, sql, params...)
+	if err != nil {
+		return database.NewError("failed to execute query", err)
 	}
 
-	sql, params, err := query.ToSql()
-	if err != nil {
-		return database.NewError("failed to build query", err)
+	if tag.RowsAffected() == 0 {
+		return database.ErrNotFound
 	}
 
-	if _, err := s.db.Exec(ctx, sql, params...); err != nil {
-		return database.NewError("failed to execute query", err)
+	if err := s.updateConfigurationsSupported(ctx, tx, issuer.TenantID, issuer.CredentialsSupported); err != nil {
+		return err
 	}
 
-	return s.UpdateConfigurationsSupported(ctx, tenantID, update.CredentialsSupported)
+	if err := tx.Commit(ctx); err != nil {
+		return database.NewError("failed to commit transaction", err)
+	}
+
+	return nil
 }
 
+// UpdateConfigurationsSupported replaces the given credential configurations of the tenant within a transaction
 func (s Store) UpdateConfigurationsSupported(ctx context.Context, tenantID string, update []issuers.CredentialsSupported) error {
+	if len(update) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return database.NewError("failed to begin transaction", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if err := s.updateConfigurationsSupported(ctx, tx, tenantID, update); err != nil {
+		return err
+	}
 
+	if err := tx.Commit(ctx); err != nil {
+		return database.NewError("failed to commit transaction", err)
+	}
+
+	return nil
+}
+
+func (s Store) updateConfigurationsSupported(ctx context.Context, db executor, tenantID string, update []issuers.CredentialsSupported) error {
 	if len(update) == 0 {
 		return nil
 	}
@@ -217,11 +313,268 @@ func (s Store) UpdateConfigurationsSupported(ctx context.Context, tenantID strin
 		return database.NewError("failed to build query", err)
 	}
 
-	if _, err := s.db.Exec(ctx, sql, params...); err != nil {
+	if _, err := db.Exec(ctx, sql, params...); err != nil {
 		return database.NewError("failed to update credentials supported", err)
 	}
 
-	return s.InsertConfigurationsSupported(ctx, tenantID, cs)
+	return s.insertConfigurationsSupported(ctx, db, tenantID, cs)
+}
+
+// UpdateSignedMetadata stores the signed_metadata JWT of the tenant together with the digest of the
+// metadata it was created for
+func (s Store) UpdateSignedMetadata(ctx context.Context, tenantID, signedMetadata, digest string) error {
+	query := s.sq.
+		Update(postgres.TblIssuers).
+		Set(colSignedMetaData, signedMetadata).
+		Set(colSignedMetaDataDigest, digest).
+		Where(squirrel.Eq{colTenantId: tenantID})
+
+	sql, params, err := query.ToSql()
+	if err != nil {
+		return database.NewError("failed to build query", err)
+	}
+
+	if _, err := s.db.Exec(ctx, sql, params...); err != nil {
+		return database.NewError("failed to execute query", err)
+	}
+
+	return nil
+}
+
+// DeleteIssuerRecord removes the issuer of the tenant together with the metadata which is bound to the issuer
+// (openid configuration, jwt-vc-issuer) and its credential configurations of the given sources, an empty
+// source matches configurations without source. nil removes the configurations of every source.
+func (s Store) DeleteIssuerRecord(ctx context.Context, tenantID string, configurationSources []string) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return database.NewError("failed to begin transaction", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	tables := []string{
+		postgres.TblCredentialsSupported,
+		postgres.TblOpenIDConfigurations,
+		postgres.TblJwtVcIssuers,
+		postgres.TblIssuers,
+	}
+
+	for _, table := range tables {
+		query := s.sq.
+			Delete(table).
+			Where(squirrel.Eq{colTenantId: tenantID})
+
+		if table == postgres.TblCredentialsSupported && configurationSources != nil {
+			query = query.Where(sourceIn(configurationSources))
+		}
+
+		sql, params, err := query.ToSql()
+		if err != nil {
+			return database.NewError("failed to build query", err)
+		}
+
+		tag, err := tx.Exec(ctx, sql, params...)
+		if err != nil {
+			return database.NewError("failed to execute query", err)
+		}
+
+		if table == postgres.TblIssuers && tag.RowsAffected() == 0 {
+			return database.ErrNotFound
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return database.NewError("failed to commit transaction", err)
+	}
+
+	return nil
+}
+
+// DeleteConfigurationRecord removes a single credential configuration of the tenant. The modified_at of the
+// issuer is updated, so the modification time of the metadata reflects the removal. last_seen is left alone,
+// as it tells when the issuer was registered.
+func (s Store) DeleteConfigurationRecord(ctx context.Context, tenantID, configurationID string) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return database.NewError("failed to begin transaction", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	sql, params, err := s.sq.
+		Delete(postgres.TblCredentialsSupported).
+		Where(squirrel.Eq{colTenantId: tenantID}).
+		Where(squirrel.Eq{colCredentialConfigurationID: configurationID}).
+		ToSql()
+	if err != nil {
+		return database.NewError("failed to build query", err)
+	}
+
+	tag, err := tx.Exec(ctx, sql, params...)
+	if err != nil {
+		return database.NewError("failed to execute query", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return database.ErrNotFound
+	}
+
+	sql, params, err = s.sq.
+		Update(postgres.TblIssuers).
+		Set(colModifiedAt, time.Now()).
+		Where(squirrel.Eq{colTenantId: tenantID}).
+		ToSql()
+	if err != nil {
+		return database.NewError("failed to build query", err)
+	}
+
+	if _, err := tx.Exec(ctx, sql, params...); err != nil {
+		return database.NewError("failed to execute query", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return database.NewError("failed to commit transaction", err)
+	}
+
+	return nil
+}
+
+// DeleteExpiredConfigurations removes all credential configurations of the source which were not seen since
+// before and returns them. The issuers of the affected tenants are touched like in DeleteConfigurationRecord.
+func (s Store) DeleteExpiredConfigurations(ctx context.Context, source string, before time.Time) ([]issuers.CredentialsSupported, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, database.NewError("failed to begin transaction", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	sql, params, err := s.sq.
+		Delete(postgres.TblCredentialsSupported).
+		Where(squirrel.Lt{colLastSeen: before}).
+		Where(squirrel.Eq{colSource: source}).
+		Suffix(fmt.Sprintf("RETURNING %s, %s, %s", colTenantId, colCredentialConfigurationID, colLastSeen)).
+		ToSql()
+	if err != nil {
+		return nil, database.NewError("failed to build query", err)
+	}
+
+	rows, err := tx.Query(ctx, sql, params...)
+	if err != nil {
+		return nil, database.NewError("failed to execute query", err)
+	}
+
+	var out []issuers.CredentialsSupported
+	tenants := make([]string, 0)
+	for rows.Next() {
+		var cs issuers.CredentialsSupported
+		if err := rows.Scan(&cs.TenantID, &cs.CredentialConfigurationID, &cs.LastSeen); err != nil {
+			rows.Close()
+			return nil, database.NewError("failed to scan", err)
+		}
+
+		out = append(out, cs)
+		tenants = append(tenants, cs.TenantID)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return nil, database.NewError("failed to execute query", err)
+	}
+
+	if len(out) == 0 {
+		return nil, nil
+	}
+
+	sql, params, err = s.sq.
+		Update(postgres.TblIssuers).
+		Set(colModifiedAt, time.Now()).
+		Where(squirrel.Eq{colTenantId: tenants}).
+		ToSql()
+	if err != nil {
+		return nil, database.NewError("failed to build query", err)
+	}
+
+	if _, err := tx.Exec(ctx, sql, params...); err != nil {
+		return nil, database.NewError("failed to execute query", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, database.NewError("failed to commit transaction", err)
+	}
+
+	return out, nil
+}
+
+// ListTenants returns all tenants which have an issuer or credential configurations
+func (s Store) ListTenants(ctx context.Context) ([]string, error) {
+	query := s.sq.
+		Select(colTenantId).
+		From(postgres.TblIssuers).
+		Suffix(fmt.Sprintf("UNION SELECT %s FROM %s", colTenantId, postgres.TblCredentialsSupported))
+
+	sql, params, err := query.ToSql()
+	if err != nil {
+		return nil, database.NewError("failed to build query", err)
+	}
+
+	rows, err := s.db.Query(ctx, sql, params...)
+	if err != nil {
+		return nil, database.NewError("failed to execute query", err)
+	}
+	defer rows.Close()
+
+	tenants := make([]string, 0)
+	for rows.Next() {
+		var tenant string
+		if err := rows.Scan(&tenant); err != nil {
+			return nil, database.NewError("failed to scan", err)
+		}
+
+		tenants = append(tenants, tenant)
+	}
+
+	return tenants, rows.Err()
+}
+
+// GetLastModified returns the latest last_seen of the issuer record and its credential configurations or the
+// last removal of a configuration, without loading the records themselves
+func (s Store) GetLastModified(ctx context.Context, tenantID string) (time.Time, error) {
+	query := s.sq.
+		Select(fmt.Sprintf(
+			"GREATEST(%s, %s, MAX(%s))",
+			postgres.Prepend(postgres.TblIssuers, colLastSeen),
+			postgres.Prepend(postgres.TblIssuers, colModifiedAt),
+			postgres.Prepend(postgres.TblCredentialsSupported, colLastSeen),
+		)).
+		From(postgres.TblIssuers).
+		LeftJoin(fmt.Sprintf(
+			"%s ON %s.%s=%s.%s",
+			postgres.TblCredentialsSupported,
+			postgres.TblIssuers, colTenantId,
+			postgres.TblCredentialsSupported, colTenantId,
+		)).
+		Where(squirrel.Eq{postgres.Prepend(postgres.TblIssuers, colTenantId): tenantID}).
+		GroupBy(postgres.Prepend(postgres.TblIssuers, colLastSeen), postgres.Prepend(postgres.TblIssuers, colModifiedAt))
+
+	sql, params, err := query.ToSql()
+	if err != nil {
+		return time.Time{}, database.NewError("failed to build query", err)
+	}
+
+	var lastModified time.Time
+	if err := s.db.QueryRow(ctx, sql, params...).Scan(&lastModified); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return time.Time{}, database.ErrNotFound
+		}
+
+		return time.Time{}, database.NewError("failed to execute query", err)
+	}
+
+	return lastModified, nil
 }
 
 func (s Store) listIssuers(ctx context.Context, orderBy string, where ...any) ([]issuers.Issuer, error) {
@@ -230,15 +583,15 @@ func (s Store) listIssuers(ctx context.Context, orderBy string, where ...any) ([
 		colAuthorizationServers, colCredentialEndpoint,
 		colBatchCredentialEndpoint, colDeferredCredentialEndpoint,
 		colCredentialResponseEncryption, colDisplay,
-		colFirstSeen, colLastSeen, colSignedMetaData,
-		colNotificationEndpoint, colCredentialIdentifiersSupported,
+		colFirstSeen, colLastSeen, colSignedMetaData, colSignedMetaDataDigest,
+		colNotificationEndpoint, colCredentialIdentifiersSupported, colSource,
 	)
 
 	columns = append(columns, postgres.PrependAll(postgres.TblCredentialsSupported,
 		colCredentialConfigurationID, colFormat, colScope,
 		colCryptographicBindingMethodsSupported, colSigningAlgValuesSupported,
 		colCredentialDefinition, colProofTypesSupported, colSchema, colSubject, colDisplay, colVct,
-		colClaims, colOrder, colFirstSeen, colLastSeen,
+		colClaims, colOrder, colFirstSeen, colLastSeen, colSource,
 	)...)
 
 	query := s.sq.
@@ -272,23 +625,28 @@ func (s Store) listIssuers(ctx context.Context, orderBy string, where ...any) ([
 	for rows.Next() {
 		var issuer issuers.Issuer
 		var csr issuers.CredentialSupportedRow
+		var source *string
 
 		err := rows.Scan(
 			&issuer.TenantID, &issuer.CredentialIssuer,
 			&issuer.AuthorizationServers, &issuer.CredentialEndpoint,
 			&issuer.BatchCredentialEndpoint, &issuer.DeferredCredentialEndpoint,
 			&issuer.CredentialResponseEncryption, &issuer.Display,
-			&issuer.FirstSeen, &issuer.LastSeen, &issuer.SignedMetadata, &issuer.NotificationEndpoint, &issuer.CredentialIdentifiersSupported,
+			&issuer.FirstSeen, &issuer.LastSeen, &issuer.SignedMetadata, &issuer.SignedMetadataDigest, &issuer.NotificationEndpoint, &issuer.CredentialIdentifiersSupported,
+			&source,
 			&csr.CredentialConfigurationID, &csr.Format, &csr.Scope,
 			&csr.CryptographicBindingMethodsSupported, &csr.CryptographicSigningAlgValuesSupported,
 			&csr.CredentialDefinition, &csr.ProofTypesSupported,
 			&csr.Schema, &csr.Subject, &csr.Display, &csr.Vct, &csr.Claims, &csr.Order, &csr.FirstSeen, &csr.LastSeen,
+			&csr.Source,
 		)
 		if err != nil {
 			s.log.Error(err, "failed to scan")
 			return nil, err
 		}
 
+		issuer.Source = fromNullable(source)
+
 		// join can produce null values, if there is no matching row
 		if csr.CredentialConfigurationID != nil {
 			issuer.CredentialsSupported = []issuers.CredentialsSupported{{
@@ -307,6 +665,7 @@ func (s Store) listIssuers(ctx context.Context, orderBy string, where ...any) ([
 				Order:                                  csr.Order,
 				FirstSeen:                              csr.FirstSeen,
 				LastSeen:                               csr.LastSeen,
+				Source:                                 fromNullable(csr.Source),
 			}}
 		}
 
@@ -340,7 +699,7 @@ func (s Store) listCredentialConfigurations(ctx context.Context, orderBy string,
 		colTenantId, colCredentialConfigurationID, colFormat, colScope,
 		colCryptographicBindingMethodsSupported, colSigningAlgValuesSupported,
 		colCredentialDefinition, colProofTypesSupported, colDisplay, colSchema, colSubject, colVct,
-		colClaims, colOrder, colFirstSeen, colLastSeen)
+		colClaims, colOrder, colFirstSeen, colLastSeen, colSource)
 
 	query := s.sq.
 		Select(columns...).
@@ -362,8 +721,9 @@ func (s Store) listCredentialConfigurations(ctx context.Context, orderBy string,
 		return nil, err
 	}
 
+	defer rows.Close()
+
 	var out []issuers.CredentialsSupported
-	var previous *issuers.CredentialsSupported
 	for rows.Next() {
 		var credentialSupported issuers.CredentialsSupported
 		var csr issuers.CredentialSupportedRow
@@ -374,6 +734,7 @@ func (s Store) listCredentialConfigurations(ctx context.Context, orderBy string,
 			&csr.CryptographicBindingMethodsSupported, &csr.CryptographicSigningAlgValuesSupported,
 			&csr.CredentialDefinition, &csr.ProofTypesSupported, &csr.Display,
 			&csr.Schema, &csr.Subject, &csr.Vct, &csr.Claims, &csr.Order, &csr.FirstSeen, &csr.LastSeen,
+			&csr.Source,
 		)
 
 		if err != nil {
@@ -398,26 +759,32 @@ func (s Store) listCredentialConfigurations(ctx context.Context, orderBy string,
 			Order:                                  csr.Order,
 			FirstSeen:                              csr.FirstSeen,
 			LastSeen:                               csr.LastSeen,
+			Source:                                 fromNullable(csr.Source),
 		}
 
-		// first row
-		if previous == nil {
-			previous = &credentialSupported
-			continue
-		}
-
-		// new issuer
-		if previous.TenantID != credentialSupported.TenantID {
-			out = append(out, *previous)
-			previous = &credentialSupported
-			continue
-		}
+		out = append(out, credentialSupported)
 	}
 
-	if previous != nil {
-		// always append the last issuer
-		out = append(out, *previous)
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
 	return out, nil
 }
+
+// nullable stores an empty string as NULL, e.g. a record without source
+func nullable(value string) *string {
+	if value == "" {
+		return nil
+	}
+
+	return &value
+}
+
+func fromNullable(value *string) string {
+	if value == nil {
+		return ""
+	}
+
+	return *value
+}
@@ -0,0 +1,68 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/database"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/database/issuers"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/database/postgres"
+)
+
+const colMetadata = "metadata"
+
+func (s Store) GetJwtVcIssuerRecord(ctx context.Context, tenantID string) (*issuers.JwtVcIssuer, error) {
+	query := s.sq.
+		Select(colTenantId, colMetadata, colFirstSeen, colLastSeen).
+		From(postgres.TblJwtVcIssuers).
+		Where(squirrel.Eq{colTenantId: tenantID})
+
+	sql, params, err := query.ToSql()
+	if err != nil {
+		return nil, database.NewError("failed to build query", err)
+	}
+
+	var record issuers.JwtVcIssuer
+	err = s.db.QueryRow(ctx, sql, params...).Scan(&record.TenantID, &record.Metadata, &record.FirstSeen, &record.LastSeen)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, database.ErrNotFound
+		}
+
+		s.log.Error(err, "failed to scan")
+		return nil, database.NewError("failed to execute query", err)
+	}
+
+	return &record, nil
+}
+
+// UpsertJwtVcIssuerRecord inserts the given record or, if there is already one for the tenant,
+// replaces its metadata. The first_seen timestamp of an existing record is kept.
+func (s Store) UpsertJwtVcIssuerRecord(ctx context.Context, jwtVcIssuer issuers.JwtVcIssuer) error {
+	query := s.sq.
+		Insert(postgres.TblJwtVcIssuers).
+		Columns(colTenantId, colMetadata, colFirstSeen, colLastSeen).
+		Values(
+			jwtVcIssuer.TenantID, jwtVcIssuer.Metadata,
+			jwtVcIssuer.FirstSeen, jwtVcIssuer.LastSeen,
+		).
+		Suffix(fmt.Sprintf(
+			"ON CONFLICT (%s) DO UPDATE SET %s = EXCLUDED.%s, %s = EXCLUDED.%s",
+			colTenantId, colMetadata, colMetadata, colLastSeen, colLastSeen,
+		))
+
+	sql, params, err := query.ToSql()
+	if err != nil {
+		return database.NewError("failed to build query", err)
+	}
+
+	if _, err := s.db.Exec(ctx, sql, params...); err != nil {
+		return database.NewError("failed to execute query", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS authorization_servers (
+    tenant_id text NOT NULL PRIMARY KEY,
+    metadata jsonb NOT NULL,
+    first_seen timestamp with time zone,
+    last_seen timestamp with time zone
+);
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS openid_configurations (
+    tenant_id text NOT NULL PRIMARY KEY,
+    configuration jsonb NOT NULL,
+    first_seen timestamp with time zone,
+    last_seen timestamp with time zone
+);
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS verifiers (
+    tenant_id text NOT NULL PRIMARY KEY,
+    client_id text NOT NULL,
+    metadata jsonb NOT NULL,
+    first_seen timestamp with time zone,
+    last_seen timestamp with time zone
+);
@@ -0,0 +1 @@
+ALTER TABLE issuers ADD signed_metadata_digest text DEFAULT NULL;
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS assets (
+    tenant_id text NOT NULL,
+    name text NOT NULL,
+    content_type text NOT NULL,
+    hash text NOT NULL,
+    content bytea NOT NULL,
+    updated_at timestamp with time zone NOT NULL,
+    PRIMARY KEY (tenant_id, name)
+);
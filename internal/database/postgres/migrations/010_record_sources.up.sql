@@ -0,0 +1,2 @@
+ALTER TABLE issuers ADD source text DEFAULT NULL;
+ALTER TABLE credentials_supported ADD source text DEFAULT NULL;
@@ -0,0 +1 @@
+ALTER TABLE issuers ADD modified_at timestamp with time zone DEFAULT NULL;
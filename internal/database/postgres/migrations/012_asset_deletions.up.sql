@@ -0,0 +1,4 @@
+CREATE TABLE IF NOT EXISTS asset_deletions (
+    tenant_id text NOT NULL PRIMARY KEY,
+    deleted_at timestamp with time zone NOT NULL
+);
@@ -10,6 +10,12 @@ import (
 const (
 	TblIssuers              = "issuers"
 	TblCredentialsSupported = "credentials_supported"
+	TblAuthorizationServers = "authorization_servers"
+	TblOpenIDConfigurations = "openid_configurations"
+	TblVerifiers            = "verifiers"
+	TblJwtVcIssuers         = "jwt_vc_issuers"
+	TblAssets               = "assets"
+	TblAssetDeletions       = "asset_deletions"
 )
 
 //go:embed migrations
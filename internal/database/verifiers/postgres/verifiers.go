@@ -0,0 +1,118 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/logr"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/database"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/database/postgres"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/database/verifiers"
+)
+
+type Store struct {
+	log logr.Logger
+	db  *pgxpool.Pool
+	sq  squirrel.StatementBuilderType
+}
+
+var _ verifiers.Store = Store{}
+
+const (
+	colTenantId  = "tenant_id"
+	colClientId  = "client_id"
+	colMetadata  = "metadata"
+	colFirstSeen = "first_seen"
+	colLastSeen  = "last_seen"
+)
+
+func NewStore(db *pgxpool.Pool, logger logr.Logger) Store {
+	return Store{
+		log: logger,
+		db:  db,
+		sq:  postgres.StmtBuilderDollar(),
+	}
+}
+
+func (s Store) GetVerifierRecord(ctx context.Context, tenantID string) (*verifiers.Verifier, error) {
+	query := s.sq.
+		Select(colTenantId, colClientId, colMetadata, colFirstSeen, colLastSeen).
+		From(postgres.TblVerifiers).
+		Where(squirrel.Eq{colTenantId: tenantID})
+
+	sql, params, err := query.ToSql()
+	if err != nil {
+		return nil, database.NewError("failed to build query", err)
+	}
+
+	var record verifiers.Verifier
+	err = s.db.QueryRow(ctx, sql, params...).Scan(
+		&record.TenantID, &record.ClientID, &record.Metadata, &record.FirstSeen, &record.LastSeen,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, database.ErrNotFound
+		}
+
+		s.log.Error(err, "failed to scan")
+		return nil, database.NewError("failed to execute query", err)
+	}
+
+	return &record, nil
+}
+
+// UpsertVerifierRecord inserts the given record or, if there is already one for the tenant,
+// replaces client id and metadata. The first_seen timestamp of an existing record is kept.
+func (s Store) UpsertVerifierRecord(ctx context.Context, verifier verifiers.Verifier) error {
+	query := s.sq.
+		Insert(postgres.TblVerifiers).
+		Columns(colTenantId, colClientId, colMetadata, colFirstSeen, colLastSeen).
+		Values(
+			verifier.TenantID, verifier.ClientID, verifier.Metadata,
+			verifier.FirstSeen, verifier.LastSeen,
+		).
+		Suffix(fmt.Sprintf(
+			"ON CONFLICT (%s) DO UPDATE SET %s = EXCLUDED.%s, %s = EXCLUDED.%s, %s = EXCLUDED.%s",
+			colTenantId, colClientId, colClientId, colMetadata, colMetadata, colLastSeen, colLastSeen,
+		))
+
+	sql, params, err := query.ToSql()
+	if err != nil {
+		return database.NewError("failed to build query", err)
+	}
+
+	if _, err := s.db.Exec(ctx, sql, params...); err != nil {
+		return database.NewError("failed to execute query", err)
+	}
+
+	return nil
+}
+
+// DeleteVerifierRecord removes the record of the tenant
+func (s Store) DeleteVerifierRecord(ctx context.Context, tenantID string) error {
+	query := s.sq.
+		Delete(postgres.TblVerifiers).
+		Where(squirrel.Eq{colTenantId: tenantID})
+
+	sql, params, err := query.ToSql()
+	if err != nil {
+		return database.NewError("failed to build query", err)
+	}
+
+	tag, err := s.db.Exec(ctx, sql, params...)
+	if err != nil {
+		return database.NewError("failed to execute query", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return database.ErrNotFound
+	}
+
+	return nil
+}
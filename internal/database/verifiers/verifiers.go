@@ -0,0 +1,22 @@
+package verifiers
+
+import (
+	"context"
+	"time"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/types"
+)
+
+type Store interface {
+	GetVerifierRecord(ctx context.Context, tenantID string) (*Verifier, error)
+	UpsertVerifierRecord(ctx context.Context, verifier Verifier) error
+	DeleteVerifierRecord(ctx context.Context, tenantID string) error
+}
+
+type Verifier struct {
+	TenantID  string
+	ClientID  string
+	Metadata  types.VerifierMetadata
+	FirstSeen time.Time
+	LastSeen  time.Time
+}
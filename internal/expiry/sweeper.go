@@ -0,0 +1,104 @@
+package expiry
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	ctxPkg "github.com/eclipse-xfsc/microservice-core-go/pkg/ctx"
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/logr"
+	"github.com/madflojo/tasks"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/service"
+)
+
+// Sweeper periodically removes credential configurations whose plugins stopped registering them
+type Sweeper struct {
+	svc           service.IssuerService
+	expiration    time.Duration
+	interval      time.Duration
+	log           logr.Logger
+	taskScheduler *tasks.Scheduler
+
+	mu     sync.RWMutex
+	status Status
+}
+
+// Status of the sweeper as exposed in the health output
+type Status struct {
+	Expiration  string     `json:"expiration"`
+	LastRun     *time.Time `json:"last_run,omitempty"`
+	LastPruned  int        `json:"last_pruned"`
+	TotalPruned int        `json:"total_pruned"`
+	LastError   string     `json:"last_error,omitempty"`
+}
+
+func NewSweeper(svc service.IssuerService, expiration, interval time.Duration, logger logr.Logger) *Sweeper {
+	return &Sweeper{
+		svc:           svc,
+		expiration:    expiration,
+		interval:      interval,
+		log:           logger,
+		taskScheduler: tasks.New(),
+		status:        Status{Expiration: expiration.String()},
+	}
+}
+
+func (s *Sweeper) Start(ctx context.Context) error {
+	ctx = ctxPkg.WithLogger(ctx, s.log)
+
+	_, err := s.taskScheduler.Add(&tasks.Task{
+		TaskContext:       tasks.TaskContext{Context: ctx},
+		Interval:          s.interval,
+		RunSingleInstance: true,
+		FuncWithTaskContext: func(tc tasks.TaskContext) error {
+			return s.sweep(tc.Context)
+		},
+	})
+	if err != nil {
+		s.log.Error(err, "failed to create scheduler for expiry sweeper")
+		return err
+	}
+
+	return nil
+}
+
+func (s *Sweeper) Stop() {
+	s.taskScheduler.Stop()
+}
+
+func (s *Sweeper) Status() Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.status
+}
+
+func (s *Sweeper) sweep(ctx context.Context) error {
+	now := time.Now()
+
+	pruned, err := s.svc.PruneExpiredConfigurations(ctx, now.Add(-s.expiration))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.status.LastRun = &now
+	s.status.LastPruned = len(pruned)
+	s.status.TotalPruned += len(pruned)
+	s.status.LastError = ""
+
+	if err != nil {
+		s.status.LastError = err.Error()
+		return err
+	}
+
+	for _, cs := range pruned {
+		s.log.Info("pruned expired credential configuration",
+			"tenant", cs.TenantID,
+			"configuration", cs.CredentialConfigurationID,
+			"lastSeen", cs.LastSeen,
+		)
+	}
+
+	return nil
+}
@@ -13,16 +13,19 @@ import (
 
 	messaging "github.com/eclipse-xfsc/nats-message-library"
 	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/service"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/types"
 )
 
 type Gateway struct {
 	svc        service.IssuerService
+	vSvc       service.VerifierService
 	natsConfig ce.NatsConfig
 }
 
-func NewGateway(svc service.IssuerService, config ce.NatsConfig) Gateway {
+func NewGateway(svc service.IssuerService, vSvc service.VerifierService, config ce.NatsConfig) Gateway {
 	return Gateway{
 		svc:        svc,
+		vSvc:       vSvc,
 		natsConfig: config,
 	}
 }
@@ -34,6 +37,10 @@ func (gw Gateway) Run(ctx context.Context) error {
 		return gw.GetIssuerMetadata(ctx)
 	})
 
+	errGrp.Go(func() error {
+		return gw.GetVerifierMetadata(ctx)
+	})
+
 	return errGrp.Wait()
 }
 
@@ -94,3 +101,61 @@ func (gw Gateway) getIssuerMetadata(ctx context.Context, event event.Event) (*ev
 
 	return &reply, nil
 }
+
+// GetVerifierMetadata initializes a new cloudeventprovider.CloudEventProviderClient, waits for an
+// incoming types.TopicGetVerifierMetadata request and replies to it.
+// The function is blocking and never returns, once the client was successfully initialized
+func (gw Gateway) GetVerifierMetadata(ctx context.Context) error {
+	client, err := ce.New(
+		ce.Config{
+			Protocol: ce.ProtocolTypeNats,
+			Settings: gw.natsConfig,
+		},
+		ce.ConnectionTypeRep,
+		types.TopicGetVerifierMetadata,
+	)
+	if err != nil {
+		return err
+	}
+
+	log := ctxPkg.GetLogger(ctx)
+
+	for {
+		if err := client.ReplyCtx(ctx, gw.getVerifierMetadata); err != nil {
+			log.Error(err, "error during getVerifierMetadata")
+		}
+	}
+}
+
+func (gw Gateway) getVerifierMetadata(ctx context.Context, event event.Event) (*event.Event, error) {
+	var req types.GetVerifierMetadataReq
+	if err := event.DataAs(&req); err != nil {
+		return nil, err
+	}
+
+	verifier, err := gw.vSvc.GetVerifier(ctx, req.TenantId)
+	if err != nil {
+		return nil, err
+	}
+
+	output := types.GetVerifierMetadataReply{
+		Reply: common.Reply{
+			TenantId:  req.TenantId,
+			RequestId: req.RequestId,
+			Error:     nil,
+		},
+		Verifier: verifier,
+	}
+
+	data, err := json.Marshal(output)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := ce.NewEvent(messaging.SourceWellKnownService, types.EventTypeGetVerifierMetadata, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &reply, nil
+}
@@ -0,0 +1,451 @@
+package rest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/auth"
+	ctxPkg "github.com/eclipse-xfsc/microservice-core-go/pkg/ctx"
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/logr"
+	"github.com/eclipse-xfsc/oid4-vci-vp-library/model/credential"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/config"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/database"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/service"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/types"
+)
+
+// AdminGateway serves the admin API, which manages the issuer, credential configurations and assets of a
+// tenant in the database
+type AdminGateway struct {
+	conf     config.AdminConfig
+	svc      service.IssuerService
+	assetSvc service.AssetService
+}
+
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+func NewAdminGateway(conf config.AdminConfig, svc service.IssuerService, assetSvc service.AssetService) AdminGateway {
+	return AdminGateway{
+		conf:     conf,
+		svc:      svc,
+		assetSvc: assetSvc,
+	}
+}
+
+// Authenticate adapts the bearer token middleware of microservice-core-go to gin
+func Authenticate(middleware *auth.Middleware) gin.HandlerFunc {
+	handler := middleware.Handler()
+
+	return func(c *gin.Context) {
+		authenticated := false
+		handler(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			authenticated = true
+		})).ServeHTTP(c.Writer, c.Request)
+
+		if !authenticated {
+			// the middleware already wrote the 401
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// AuthorizeTenant restricts the token to the tenants listed in the configured claim, tokens without the claim
+// are rejected. The token was verified by Authenticate before, so the claims are only decoded.
+func (gw AdminGateway) AuthorizeTenant(c *gin.Context) {
+	if gw.conf.TenantClaim == "" {
+		// required by the config, never authorize every tenant
+		c.AbortWithStatusJSON(http.StatusForbidden, ErrorResponse{Error: "no tenant claim configured"})
+		return
+	}
+
+	claims, err := tokenClaims(c.GetHeader("Authorization"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	tenantId := c.Param("tenantId")
+
+	switch value := claims[gw.conf.TenantClaim].(type) {
+	case string:
+		if value == tenantId {
+			c.Next()
+			return
+		}
+	case []interface{}:
+		for _, v := range value {
+			if v == tenantId {
+				c.Next()
+				return
+			}
+		}
+	}
+
+	c.AbortWithStatusJSON(http.StatusForbidden, ErrorResponse{Error: "token is not valid for tenant " + tenantId})
+}
+
+func tokenClaims(authorization string) (map[string]interface{}, error) {
+	parts := strings.Split(strings.TrimPrefix(authorization, "Bearer "), ".")
+	if len(parts) != 3 {
+		return nil, errors.New("invalid token")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid token payload: %w", err)
+	}
+
+	claims := make(map[string]interface{})
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("invalid token payload: %w", err)
+	}
+
+	return claims, nil
+}
+
+// GetIssuerHandler godoc
+//
+// @Summary		Get issuer
+// @Description	Returns the stored issuer of the tenant including its credential configurations
+// @Tags		admin
+// @Produce		json
+// @Param		tenantId	path		string	true	"Tenant ID"
+// @Success		200			{object}	credential.IssuerMetadata
+// @Failure		401			{object}	ErrorResponse
+// @Failure		404			{object}	ErrorResponse
+// @Security	BearerAuth
+// @Router		/admin/issuer [get]
+func (gw AdminGateway) GetIssuerHandler(c *gin.Context) {
+	metadata, err := gw.svc.GetIssuer(c, c.Param("tenantId"), true)
+	if err != nil {
+		abortWithServiceError(c, ctxPkg.GetLogger(c), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, metadata)
+}
+
+// CreateIssuerHandler godoc
+//
+// @Summary		Create issuer
+// @Description	Stores the issuer of a tenant which has none yet. Contained credential configurations are stored as well.
+// @Tags		admin
+// @Accept		json
+// @Param		tenantId	path		string						true	"Tenant ID"
+// @Param		issuer		body		credential.IssuerMetadata	true	"Issuer metadata"
+// @Success		201
+// @Failure		400			{object}	ErrorResponse
+// @Failure		401			{object}	ErrorResponse
+// @Failure		409			{object}	ErrorResponse
+// @Security	BearerAuth
+// @Router		/admin/issuer [post]
+func (gw AdminGateway) CreateIssuerHandler(c *gin.Context) {
+	var metadata credential.IssuerMetadata
+	if !bindAndValidate(c, &metadata, types.ValidateIssuerMetadata) {
+		return
+	}
+
+	if err := gw.svc.CreateIssuer(c, c.Param("tenantId"), metadata); err != nil {
+		abortWithServiceError(c, ctxPkg.GetLogger(c), err)
+		return
+	}
+
+	c.Status(http.StatusCreated)
+}
+
+// UpdateIssuerHandler godoc
+//
+// @Summary		Update issuer
+// @Description	Replaces the issuer of the tenant, optional fields missing in the body are removed. credential_issuer can not be changed. Contained credential configurations are added or replaced, others are kept.
+// @Tags		admin
+// @Accept		json
+// @Param		tenantId	path		string						true	"Tenant ID"
+// @Param		issuer		body		credential.IssuerMetadata	true	"Issuer metadata"
+// @Success		204
+// @Failure		400			{object}	ErrorResponse
+// @Failure		401			{object}	ErrorResponse
+// @Failure		404			{object}	ErrorResponse
+// @Failure		409			{object}	ErrorResponse
+// @Security	BearerAuth
+// @Router		/admin/issuer [put]
+func (gw AdminGateway) UpdateIssuerHandler(c *gin.Context) {
+	var metadata credential.IssuerMetadata
+	if !bindAndValidate(c, &metadata, types.ValidateIssuerMetadata) {
+		return
+	}
+
+	if err := gw.svc.UpdateIssuer(c, c.Param("tenantId"), metadata); err != nil {
+		abortWithServiceError(c, ctxPkg.GetLogger(c), err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// DeleteIssuerHandler godoc
+//
+// @Summary		Delete issuer
+// @Description	Removes the issuer of the tenant including all credential configurations
+// @Tags		admin
+// @Param		tenantId	path		string	true	"Tenant ID"
+// @Success		204
+// @Failure		401			{object}	ErrorResponse
+// @Failure		404			{object}	ErrorResponse
+// @Security	BearerAuth
+// @Router		/admin/issuer [delete]
+func (gw AdminGateway) DeleteIssuerHandler(c *gin.Context) {
+	if err := gw.svc.DeleteIssuer(c, c.Param("tenantId")); err != nil {
+		abortWithServiceError(c, ctxPkg.GetLogger(c), err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListConfigurationsHandler godoc
+//
+// @Summary		List credential configurations
+// @Description	Returns all credential configurations of the tenant by their id
+// @Tags		admin
+// @Produce		json
+// @Param		tenantId	path		string	true	"Tenant ID"
+// @Success		200			{object}	map[string]credential.CredentialConfiguration
+// @Failure		401			{object}	ErrorResponse
+// @Security	BearerAuth
+// @Router		/admin/configurations [get]
+func (gw AdminGateway) ListConfigurationsHandler(c *gin.Context) {
+	configurations, err := gw.svc.ListConfigurations(c, c.Param("tenantId"), true)
+	if err != nil {
+		abortWithServiceError(c, ctxPkg.GetLogger(c), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, configurations)
+}
+
+// GetConfigurationHandler godoc
+//
+// @Summary		Get credential configuration
+// @Tags		admin
+// @Produce		json
+// @Param		tenantId		path		string	true	"Tenant ID"
+// @Param		configurationId	path		string	true	"Credential configuration ID"
+// @Success		200				{object}	credential.CredentialConfiguration
+// @Failure		401				{object}	ErrorResponse
+// @Failure		404				{object}	ErrorResponse
+// @Security	BearerAuth
+// @Router		/admin/configurations/{configurationId} [get]
+func (gw AdminGateway) GetConfigurationHandler(c *gin.Context) {
+	configuration, err := gw.svc.GetConfiguration(c, c.Param("tenantId"), c.Param("configurationId"), true)
+	if err != nil {
+		abortWithServiceError(c, ctxPkg.GetLogger(c), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, configuration)
+}
+
+// CreateConfigurationHandler godoc
+//
+// @Summary		Create credential configuration
+// @Tags		admin
+// @Accept		json
+// @Param		tenantId		path		string								true	"Tenant ID"
+// @Param		configurationId	path		string								true	"Credential configuration ID"
+// @Param		configuration	body		credential.CredentialConfiguration	true	"Credential configuration"
+// @Success		201
+// @Failure		400				{object}	ErrorResponse
+// @Failure		401				{object}	ErrorResponse
+// @Failure		409				{object}	ErrorResponse
+// @Security	BearerAuth
+// @Router		/admin/configurations/{configurationId} [post]
+func (gw AdminGateway) CreateConfigurationHandler(c *gin.Context) {
+	var configuration credential.CredentialConfiguration
+	if !bindAndValidate(c, &configuration, types.ValidateCredentialConfiguration) {
+		return
+	}
+
+	if err := gw.svc.CreateConfiguration(c, c.Param("tenantId"), c.Param("configurationId"), configuration); err != nil {
+		abortWithServiceError(c, ctxPkg.GetLogger(c), err)
+		return
+	}
+
+	c.Status(http.StatusCreated)
+}
+
+// UpdateConfigurationHandler godoc
+//
+// @Summary		Update credential configuration
+// @Tags		admin
+// @Accept		json
+// @Param		tenantId		path		string								true	"Tenant ID"
+// @Param		configurationId	path		string								true	"Credential configuration ID"
+// @Param		configuration	body		credential.CredentialConfiguration	true	"Credential configuration"
+// @Success		204
+// @Failure		400				{object}	ErrorResponse
+// @Failure		401				{object}	ErrorResponse
+// @Failure		404				{object}	ErrorResponse
+// @Security	BearerAuth
+// @Router		/admin/configurations/{configurationId} [put]
+func (gw AdminGateway) UpdateConfigurationHandler(c *gin.Context) {
+	var configuration credential.CredentialConfiguration
+	if !bindAndValidate(c, &configuration, types.ValidateCredentialConfiguration) {
+		return
+	}
+
+	if err := gw.svc.UpdateConfiguration(c, c.Param("tenantId"), c.Param("configurationId"), configuration); err != nil {
+		abortWithServiceError(c, ctxPkg.GetLogger(c), err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// DeleteConfigurationHandler godoc
+//
+// @Summary		Delete credential configuration
+// @Tags		admin
+// @Param		tenantId		path		string	true	"Tenant ID"
+// @Param		configurationId	path		string	true	"Credential configuration ID"
+// @Success		204
+// @Failure		401				{object}	ErrorResponse
+// @Failure		404				{object}	ErrorResponse
+// @Security	BearerAuth
+// @Router		/admin/configurations/{configurationId} [delete]
+func (gw AdminGateway) DeleteConfigurationHandler(c *gin.Context) {
+	if err := gw.svc.DeleteConfiguration(c, c.Param("tenantId"), c.Param("configurationId")); err != nil {
+		abortWithServiceError(c, ctxPkg.GetLogger(c), err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListAssetsHandler godoc
+//
+// @Summary		List assets
+// @Description	Returns the uploaded assets of the tenant by their name, without content
+// @Tags		admin
+// @Produce		json
+// @Param		tenantId	path		string	true	"Tenant ID"
+// @Success		200			{object}	map[string]types.Asset
+// @Failure		401			{object}	ErrorResponse
+// @Security	BearerAuth
+// @Router		/admin/assets [get]
+func (gw AdminGateway) ListAssetsHandler(c *gin.Context) {
+	assets, err := gw.assetSvc.ListAssets(c, c.Param("tenantId"))
+	if err != nil {
+		abortWithServiceError(c, ctxPkg.GetLogger(c), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, assets)
+}
+
+// UploadAssetHandler godoc
+//
+// @Summary		Upload asset
+// @Description	Stores the body as asset of the tenant or replaces the asset of that name. The content type is derived from the extension of the name or, if unknown, from the content. Relative logo URLs of the metadata (e.g. logo.png) are rewritten to the URL of the asset.
+// @Tags		admin
+// @Accept		octet-stream
+// @Produce		json
+// @Param		tenantId	path		string	true	"Tenant ID"
+// @Param		name		path		string	true	"Asset name, e.g. logo.png"
+// @Param		asset		body		string	true	"Asset content"
+// @Success		200			{object}	types.Asset
+// @Failure		400			{object}	ErrorResponse
+// @Failure		401			{object}	ErrorResponse
+// @Failure		413			{object}	ErrorResponse
+// @Security	BearerAuth
+// @Router		/admin/assets/{name} [put]
+func (gw AdminGateway) UploadAssetHandler(c *gin.Context) {
+	name := c.Param("name")
+	if !types.ValidAssetName(name) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrorResponse{Error: "invalid asset name " + name})
+		return
+	}
+
+	content, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, types.MaxAssetSize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, ErrorResponse{Error: fmt.Sprintf("asset exceeds %d bytes", types.MaxAssetSize)})
+			return
+		}
+
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrorResponse{Error: "invalid body: " + err.Error()})
+		return
+	}
+
+	if len(content) == 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrorResponse{Error: "empty asset"})
+		return
+	}
+
+	asset, err := gw.assetSvc.UpsertAsset(c, c.Param("tenantId"), name, content)
+	if err != nil {
+		abortWithServiceError(c, ctxPkg.GetLogger(c), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, asset)
+}
+
+// DeleteAssetHandler godoc
+//
+// @Summary		Delete asset
+// @Tags		admin
+// @Param		tenantId	path		string	true	"Tenant ID"
+// @Param		name		path		string	true	"Asset name"
+// @Success		204
+// @Failure		401			{object}	ErrorResponse
+// @Failure		404			{object}	ErrorResponse
+// @Security	BearerAuth
+// @Router		/admin/assets/{name} [delete]
+func (gw AdminGateway) DeleteAssetHandler(c *gin.Context) {
+	if err := gw.assetSvc.DeleteAsset(c, c.Param("tenantId"), c.Param("name")); err != nil {
+		abortWithServiceError(c, ctxPkg.GetLogger(c), err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func bindAndValidate[T any](c *gin.Context, v *T, validate func(*T) error) bool {
+	if err := c.ShouldBindJSON(v); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrorResponse{Error: "invalid body: " + err.Error()})
+		return false
+	}
+
+	if err := validate(v); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return false
+	}
+
+	return true
+}
+
+func abortWithServiceError(c *gin.Context, log logr.Logger, err error) {
+	switch {
+	case errors.Is(err, database.ErrNotFound):
+		c.AbortWithStatusJSON(http.StatusNotFound, ErrorResponse{Error: "not found"})
+	case errors.Is(err, service.ErrAlreadyExists), errors.Is(err, service.ErrCredentialIssuerImmutable):
+		c.AbortWithStatusJSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+	default:
+		log.Error(err, "admin request failed")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, ErrorResponse{Error: "internal error"})
+	}
+}
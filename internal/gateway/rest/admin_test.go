@@ -0,0 +1,69 @@
+package rest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/config"
+)
+
+func TestAuthorizeTenant(t *testing.T) {
+	tests := []struct {
+		name        string
+		tenantClaim string
+		claims      map[string]interface{}
+		token       string
+		wantStatus  int
+	}{
+		{name: "string claim", tenantClaim: "tenant", claims: map[string]interface{}{"tenant": "tenant-a"}, wantStatus: http.StatusOK},
+		{name: "array claim", tenantClaim: "tenants", claims: map[string]interface{}{"tenants": []string{"tenant-b", "tenant-a"}}, wantStatus: http.StatusOK},
+		{name: "missing claim", tenantClaim: "tenants", claims: map[string]interface{}{"sub": "admin"}, wantStatus: http.StatusForbidden},
+		{name: "foreign tenant", tenantClaim: "tenant", claims: map[string]interface{}{"tenant": "tenant-b"}, wantStatus: http.StatusForbidden},
+		{name: "foreign tenants", tenantClaim: "tenants", claims: map[string]interface{}{"tenants": []string{"tenant-b"}}, wantStatus: http.StatusForbidden},
+		{name: "claim of other type", tenantClaim: "tenant", claims: map[string]interface{}{"tenant": map[string]string{"id": "tenant-a"}}, wantStatus: http.StatusForbidden},
+		{name: "no tenant claim configured", claims: map[string]interface{}{"tenant": "tenant-a"}, wantStatus: http.StatusForbidden},
+		{name: "malformed token", tenantClaim: "tenant", token: "Bearer invalid", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			gw := AdminGateway{conf: config.AdminConfig{TenantClaim: tt.tenantClaim}}
+			router.GET("/v1/tenants/:tenantId/admin/issuer", gw.AuthorizeTenant, func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			token := tt.token
+			if token == "" {
+				token = "Bearer " + unsignedToken(t, tt.claims)
+			}
+
+			request := httptest.NewRequest(http.MethodGet, "/v1/tenants/tenant-a/admin/issuer", nil)
+			request.Header.Set("Authorization", token)
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, recorder.Code)
+			}
+		})
+	}
+}
+
+// unsignedToken encodes the claims as JWT, AuthorizeTenant only runs after Authenticate verified the signature
+func unsignedToken(t *testing.T, claims map[string]interface{}) string {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encode := base64.RawURLEncoding.EncodeToString
+	return encode([]byte(`{"alg":"none"}`)) + "." + encode(payload) + "." + encode([]byte("signature"))
+}
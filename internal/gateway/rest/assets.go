@@ -0,0 +1,150 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	ctxPkg "github.com/eclipse-xfsc/microservice-core-go/pkg/ctx"
+	"github.com/eclipse-xfsc/oid4-vci-vp-library/model/credential"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/common"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/importer"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/importer/layout"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/types"
+)
+
+const (
+	// immutableCacheControl is sent for content addressed URLs, whose content never changes
+	immutableCacheControl = "public, max-age=31536000, immutable"
+	// assetSecurityPolicy prevents scripts of uploaded assets (e.g. SVG) from running in the origin of the service
+	assetSecurityPolicy = "default-src 'none'; style-src 'unsafe-inline'; sandbox"
+)
+
+var assetHashPattern = regexp.MustCompile(`^[0-9a-f]{16}$`)
+
+// AssetHandler serves the assets of the tenant. Assets are requested by <hash>/<name> as linked in the
+// metadata, which can be cached forever, or by <name>, which has to be revalidated. Outdated hashes are
+// redirected to the current URL of the asset.
+func (gw Gateway) AssetHandler(c *gin.Context) {
+	log := ctxPkg.GetLogger(c)
+
+	tenantId := c.Param("tenantId")
+	if tenantId == "" {
+		c.JSON(404, "Not found.")
+		return
+	}
+
+	path := strings.TrimPrefix(c.Param("asset"), "/")
+
+	hash, name, hashed := strings.Cut(path, "/")
+	if !hashed || !assetHashPattern.MatchString(hash) {
+		hash, name, hashed = "", path, false
+	}
+
+	asset, err := gw.imp.GetAsset(c, tenantId, name)
+	if errors.Is(err, importer.ErrNotFound) && hashed {
+		// a name which starts with a segment like a hash
+		hash, name, hashed = "", path, false
+		asset, err = gw.imp.GetAsset(c, tenantId, name)
+	}
+	if err != nil {
+		abortWithImporterError(c, log, err)
+		return
+	}
+
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Content-Security-Policy", assetSecurityPolicy)
+
+	if hashed && hash != asset.Hash {
+		prefix := strings.TrimSuffix(c.Request.URL.Path, c.Param("asset"))
+		c.Header("Cache-Control", "no-cache")
+		c.Redirect(http.StatusFound, prefix+"/"+asset.Hash+"/"+asset.Name)
+		return
+	}
+
+	etag := `"` + asset.Hash + `"`
+	c.Header("ETag", etag)
+
+	if hashed {
+		c.Header("Cache-Control", immutableCacheControl)
+	} else {
+		c.Header("Cache-Control", "no-cache")
+	}
+
+	if !asset.ModTime.IsZero() {
+		c.Header("Last-Modified", asset.ModTime.UTC().Format(http.TimeFormat))
+	}
+
+	if ifNoneMatch := c.GetHeader("If-None-Match"); ifNoneMatch != "" {
+		if matchesETag(ifNoneMatch, etag) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+	} else if !modifiedSince(c, asset.ModTime) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, asset.ContentType, asset.Content)
+}
+
+// rewriteLogos replaces relative logo URLs of the issuer and its credential configurations by the content
+// addressed URLs of the assets of the tenant. Without assets the metadata is kept as it is.
+func (gw Gateway) rewriteLogos(c *gin.Context, tenantId string, metadata *credential.IssuerMetadata) error {
+	assets, err := gw.imp.ListAssets(c, tenantId)
+	if errors.Is(err, importer.ErrNotFound) || (err == nil && len(assets) == 0) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	baseURL, err := gw.requestBaseURL(c)
+	if err != nil {
+		return err
+	}
+
+	metadata.Display = types.RewriteLogos(metadata.Display, assets, baseURL)
+
+	configurations := make(map[string]credential.CredentialConfiguration, len(metadata.CredentialConfigurationsSupported))
+	for id, configuration := range metadata.CredentialConfigurationsSupported {
+		configuration.Display = types.RewriteLogos(configuration.Display, assets, baseURL)
+		configurations[id] = configuration
+	}
+	metadata.CredentialConfigurationsSupported = configurations
+
+	return nil
+}
+
+// LegacyImageHandler redirects the URLs of the removed GIT_IMAGE_PATH and FILE_IMAGE_PATH, which served the
+// checkout as it is (<imagePath>/<tenant>/images/<name>), to the assets of the tenant.
+//
+// Deprecated: kept for one release, link the assets instead.
+func (gw Gateway) LegacyImageHandler(imagePath string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		file := c.Param("file")
+
+		tenantId, name, _ := strings.Cut(strings.TrimPrefix(file, "/"), "/")
+		name, ok := strings.CutPrefix(name, layout.ImagesDir+"/")
+		if tenantId == "" || !ok || name == "" {
+			c.JSON(404, "Not found.")
+			return
+		}
+
+		// <prefix>/v1/tenants/<tenantId of the route><imagePath><file>
+		tenants := path.Dir(strings.TrimSuffix(strings.TrimSuffix(c.Request.URL.Path, file), strings.TrimRight(imagePath, "/")))
+
+		segments := strings.Split(name, "/")
+		for i, segment := range segments {
+			segments[i] = url.PathEscape(segment)
+		}
+
+		c.Redirect(http.StatusMovedPermanently, tenants+"/"+url.PathEscape(tenantId)+common.AssetsPath+"/"+strings.Join(segments, "/"))
+	}
+}
@@ -0,0 +1,45 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/config"
+)
+
+func TestLegacyImageHandler(t *testing.T) {
+	tests := []struct {
+		name         string
+		imagePath    string
+		path         string
+		wantStatus   int
+		wantLocation string
+	}{
+		{name: "logo", imagePath: "/images", path: "/v1/tenants/t1/images/tenant-a/images/logo.png", wantStatus: http.StatusMovedPermanently, wantLocation: "/v1/tenants/tenant-a/assets/logo.png"},
+		{name: "nested", imagePath: "/images/", path: "/v1/tenants/t1/images/tenant-a/images/icons/logo%20dark.png", wantStatus: http.StatusMovedPermanently, wantLocation: "/v1/tenants/tenant-a/assets/icons/logo%20dark.png"},
+		{name: "metadata file", imagePath: "/images", path: "/v1/tenants/t1/images/tenant-a/issuer.json", wantStatus: http.StatusNotFound},
+		{name: "tenant only", imagePath: "/images", path: "/v1/tenants/t1/images/tenant-a", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			gw := NewGateway(config.GatewayConfig{}, nil, nil)
+			router.Group("/v1/tenants/:tenantId").GET("/images/*file", gw.LegacyImageHandler(tt.imagePath))
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, recorder.Code)
+			}
+			if location := recorder.Header().Get("Location"); location != tt.wantLocation {
+				t.Fatalf("expected location %s, got %s", tt.wantLocation, location)
+			}
+		})
+	}
+}
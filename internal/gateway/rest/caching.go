@@ -0,0 +1,113 @@
+package rest
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	ctxPkg "github.com/eclipse-xfsc/microservice-core-go/pkg/ctx"
+	"github.com/gin-gonic/gin"
+)
+
+// issuerLastModified returns the modification time of the issuer metadata of the tenant. Caching is best
+// effort, so errors result in a zero time, which disables Last-Modified.
+func (gw Gateway) issuerLastModified(c *gin.Context, tenantId string) time.Time {
+	lastModified, err := gw.imp.GetCredentialIssuerLastModified(c, tenantId)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return lastModified.UTC().Truncate(time.Second)
+}
+
+// notModifiedSince answers the request with 304, if it carries only If-Modified-Since and the metadata was not
+// modified since then. This avoids loading the metadata at all, so Vary has to be set before. Removals advance
+// the modification time as well.
+func (gw Gateway) notModifiedSince(c *gin.Context, tenantId string, lastModified time.Time) bool {
+	if c.GetHeader("If-None-Match") != "" || modifiedSince(c, lastModified) {
+		return false
+	}
+
+	gw.setCacheHeaders(c, tenantId, lastModified)
+	c.Status(http.StatusNotModified)
+	return true
+}
+
+// respondJSON writes v as JSON with cache validators
+func (gw Gateway) respondJSON(c *gin.Context, tenantId string, lastModified time.Time, v any) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		abortWithImporterError(c, ctxPkg.GetLogger(c), err)
+		return
+	}
+
+	gw.respond(c, tenantId, lastModified, gin.MIMEJSON+"; charset=utf-8", body)
+}
+
+// respond writes the body with ETag, Last-Modified and Cache-Control. A matching If-None-Match is answered
+// with 304; If-Modified-Since is answered by notModifiedSince before the metadata is loaded, as If-None-Match
+// takes precedence over it (RFC 9110, section 13.2.2).
+func (gw Gateway) respond(c *gin.Context, tenantId string, lastModified time.Time, contentType string, body []byte) {
+	sum := sha256.Sum256(body)
+	etag := `"` + base64.RawURLEncoding.EncodeToString(sum[:]) + `"`
+
+	c.Header("ETag", etag)
+	gw.setCacheHeaders(c, tenantId, lastModified)
+
+	if matchesETag(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, contentType, body)
+}
+
+func (gw Gateway) setCacheHeaders(c *gin.Context, tenantId string, lastModified time.Time) {
+	if !lastModified.IsZero() {
+		c.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	maxAge := gw.conf.CacheMaxAge
+	if tenantMaxAge, ok := gw.conf.CacheMaxAgeTenants[tenantId]; ok {
+		maxAge = tenantMaxAge
+	}
+
+	// without max-age clients may still store the response, but have to revalidate it on every use
+	if maxAge > 0 {
+		c.Header("Cache-Control", "public, max-age="+strconv.Itoa(maxAge))
+	} else {
+		c.Header("Cache-Control", "no-cache")
+	}
+}
+
+// modifiedSince reports, whether the content was modified after If-Modified-Since. Requests without (valid)
+// header and content without modification time are always considered modified.
+func modifiedSince(c *gin.Context, lastModified time.Time) bool {
+	header := c.GetHeader("If-Modified-Since")
+	if header == "" || lastModified.IsZero() {
+		return true
+	}
+
+	since, err := http.ParseTime(header)
+	if err != nil {
+		return true
+	}
+
+	return lastModified.Truncate(time.Second).After(since)
+}
+
+// matchesETag compares the If-None-Match header weakly with the etag
+func matchesETag(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
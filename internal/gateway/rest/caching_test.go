@@ -0,0 +1,140 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-library/model/credential"
+	"github.com/gin-gonic/gin"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/config"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/importer"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/types"
+)
+
+// metadataImporter serves fixed issuer metadata and counts how often it was loaded
+type metadataImporter struct {
+	importer.Importer
+
+	metadata     credential.IssuerMetadata
+	lastModified time.Time
+	loads        int
+}
+
+func (m *metadataImporter) GetCredentialIssuerMetadata(context.Context, string) (*credential.IssuerMetadata, error) {
+	m.loads++
+	metadata := m.metadata
+	return &metadata, nil
+}
+
+func (m *metadataImporter) GetCredentialIssuerLastModified(context.Context, string) (time.Time, error) {
+	return m.lastModified, nil
+}
+
+func (m *metadataImporter) ListAssets(context.Context, string) (map[string]types.Asset, error) {
+	return nil, importer.ErrNotFound
+}
+
+func TestCredentialIssuerConditionalRequests(t *testing.T) {
+	lastModified := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		headers     map[string]string
+		etag        bool
+		wantStatus  int
+		wantLoading bool
+	}{
+		{name: "unconditional", wantStatus: http.StatusOK, wantLoading: true},
+		{
+			name:       "not modified since",
+			headers:    map[string]string{"If-Modified-Since": lastModified.Format(http.TimeFormat)},
+			wantStatus: http.StatusNotModified,
+		},
+		{
+			name:        "modified since",
+			headers:     map[string]string{"If-Modified-Since": lastModified.Add(-time.Hour).Format(http.TimeFormat)},
+			wantStatus:  http.StatusOK,
+			wantLoading: true,
+		},
+		{
+			name:        "invalid date",
+			headers:     map[string]string{"If-Modified-Since": "yesterday"},
+			wantStatus:  http.StatusOK,
+			wantLoading: true,
+		},
+		{
+			name:        "matching etag",
+			etag:        true,
+			wantStatus:  http.StatusNotModified,
+			wantLoading: true,
+		},
+		{
+			name:        "etag takes precedence",
+			headers:     map[string]string{"If-None-Match": `"other"`, "If-Modified-Since": lastModified.Format(http.TimeFormat)},
+			wantStatus:  http.StatusOK,
+			wantLoading: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			imp := &metadataImporter{
+				metadata:     credential.IssuerMetadata{CredentialIssuer: "https://issuer.example"},
+				lastModified: lastModified,
+			}
+			gw := NewGateway(config.GatewayConfig{
+				PublicURL:                 "https://wellknown.example",
+				CredentialIssuerHeaderKey: "X-Credential-Issuer",
+				LocalizeDisplay:           true,
+			}, imp, nil)
+
+			router := gin.New()
+			router.GET("/v1/tenants/:tenantId/.well-known/openid-credential-issuer", gw.WellKnownCredentialIssuerHandler)
+
+			serve := func(headers map[string]string) *httptest.ResponseRecorder {
+				request := httptest.NewRequest(http.MethodGet, "/v1/tenants/tenant/.well-known/openid-credential-issuer", nil)
+				for key, value := range headers {
+					request.Header.Set(key, value)
+				}
+
+				recorder := httptest.NewRecorder()
+				router.ServeHTTP(recorder, request)
+				return recorder
+			}
+
+			headers := tt.headers
+			if tt.etag {
+				headers = map[string]string{"If-None-Match": serve(nil).Header().Get("ETag")}
+				imp.loads = 0
+			}
+
+			recorder := serve(headers)
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, recorder.Code)
+			}
+			if (imp.loads > 0) != tt.wantLoading {
+				t.Fatalf("expected loading the metadata %v, got %d loads", tt.wantLoading, imp.loads)
+			}
+			if got := recorder.Header().Get("Last-Modified"); got != lastModified.Format(http.TimeFormat) {
+				t.Fatalf("expected Last-Modified %s, got %s", lastModified.Format(http.TimeFormat), got)
+			}
+
+			vary := recorder.Header().Values("Vary")
+			for _, header := range []string{"Accept", "Accept-Language", "X-Credential-Issuer"} {
+				found := false
+				for _, value := range vary {
+					found = found || value == header
+				}
+				if !found {
+					t.Fatalf("expected %s in Vary, got %v", header, vary)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/common"
+)
+
+// HealthResponse extends the health response of microservice-core-go by the details of the background jobs
+type HealthResponse struct {
+	Status  bool           `json:"status"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// HealthHandler replaces the default health handler of the server
+func HealthHandler(env *common.Environment) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, HealthResponse{
+			Status:  env.IsHealthy(),
+			Details: env.HealthDetails(),
+		})
+	}
+}
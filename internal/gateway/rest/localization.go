@@ -0,0 +1,202 @@
+package rest
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-library/model/credential"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+)
+
+// localizer reduces the display arrays of a metadata document to the locale which matches the Accept-Language
+// header best. Display arrays without a matching locale are left untouched, so clients always get a display.
+type localizer struct {
+	accepted []language.Tag
+	selected map[string]bool
+}
+
+// newLocalizer parses the Accept-Language header of the request. It returns nil, if localization is disabled
+// or the header is missing or invalid.
+func (gw Gateway) newLocalizer(c *gin.Context) *localizer {
+	if !gw.conf.LocalizeDisplay {
+		return nil
+	}
+
+	c.Writer.Header().Add("Vary", "Accept-Language")
+
+	accepted, _, err := language.ParseAcceptLanguage(c.GetHeader("Accept-Language"))
+	if err != nil || len(accepted) == 0 {
+		return nil
+	}
+
+	return &localizer{accepted: accepted, selected: make(map[string]bool)}
+}
+
+// setContentLanguage announces the locales which were selected for the response
+func (l *localizer) setContentLanguage(c *gin.Context) {
+	if l == nil || len(l.selected) == 0 {
+		return
+	}
+
+	locales := make([]string, 0, len(l.selected))
+	for locale := range l.selected {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+
+	c.Header("Content-Language", strings.Join(locales, ", "))
+}
+
+// match returns the locale out of the given ones which fits the accepted languages best. Regional variants
+// fall back to their base language (de-AT matches de).
+func (l *localizer) match(locales []string) (string, bool) {
+	if len(locales) == 0 {
+		return "", false
+	}
+
+	supported := make([]language.Tag, len(locales))
+	for i, locale := range locales {
+		tag, err := language.Parse(locale)
+		if err != nil {
+			tag = language.Und
+		}
+		supported[i] = tag
+	}
+
+	_, index, confidence := language.NewMatcher(supported).Match(l.accepted...)
+	if confidence == language.No {
+		return "", false
+	}
+
+	if locales[index] != "" {
+		l.selected[locales[index]] = true
+	}
+
+	return locales[index], true
+}
+
+func (l *localizer) issuerMetadata(metadata *credential.IssuerMetadata) {
+	if l == nil {
+		return
+	}
+
+	metadata.Display = l.localizedCredentials(metadata.Display)
+
+	configurations := make(map[string]credential.CredentialConfiguration, len(metadata.CredentialConfigurationsSupported))
+	for id, configuration := range metadata.CredentialConfigurationsSupported {
+		configurations[id] = l.configuration(configuration)
+	}
+	metadata.CredentialConfigurationsSupported = configurations
+}
+
+func (l *localizer) configuration(configuration credential.CredentialConfiguration) credential.CredentialConfiguration {
+	if l == nil {
+		return configuration
+	}
+
+	configuration.Display = l.localizedCredentials(configuration.Display)
+	configuration.Claims = l.claims(configuration.Claims)
+
+	if len(configuration.CredentialDefinition.CredentialSubject) > 0 {
+		subject := make(map[string]credential.CredentialSubject, len(configuration.CredentialDefinition.CredentialSubject))
+		for name, claim := range configuration.CredentialDefinition.CredentialSubject {
+			claim.Display = l.displays(claim.Display)
+			subject[name] = claim
+		}
+		configuration.CredentialDefinition.CredentialSubject = subject
+	}
+
+	return configuration
+}
+
+func (l *localizer) localizedCredentials(displays []credential.LocalizedCredential) []credential.LocalizedCredential {
+	locales := make([]string, len(displays))
+	for i, display := range displays {
+		locales[i] = display.Locale
+	}
+
+	locale, ok := l.match(locales)
+	if !ok {
+		return displays
+	}
+
+	out := make([]credential.LocalizedCredential, 0)
+	for _, display := range displays {
+		if display.Locale == locale {
+			out = append(out, display)
+		}
+	}
+
+	return out
+}
+
+func (l *localizer) displays(displays []credential.Display) []credential.Display {
+	locales := make([]string, len(displays))
+	for i, display := range displays {
+		locales[i] = display.Locale
+	}
+
+	locale, ok := l.match(locales)
+	if !ok {
+		return displays
+	}
+
+	out := make([]credential.Display, 0)
+	for _, display := range displays {
+		if display.Locale == locale {
+			out = append(out, display)
+		}
+	}
+
+	return out
+}
+
+// claims copies the (nested) claims description with localized display arrays. The original is not modified,
+// as importers may share it between requests.
+func (l *localizer) claims(claims map[string]interface{}) map[string]interface{} {
+	if claims == nil {
+		return nil
+	}
+
+	out := make(map[string]interface{}, len(claims))
+	for name, value := range claims {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			out[name] = l.claims(v)
+		case []interface{}:
+			if name == "display" {
+				out[name] = l.claimDisplays(v)
+			} else {
+				out[name] = v
+			}
+		default:
+			out[name] = v
+		}
+	}
+
+	return out
+}
+
+func (l *localizer) claimDisplays(displays []interface{}) []interface{} {
+	locales := make([]string, len(displays))
+	for i, d := range displays {
+		if display, ok := d.(map[string]interface{}); ok {
+			locales[i], _ = display["locale"].(string)
+		}
+	}
+
+	locale, ok := l.match(locales)
+	if !ok {
+		return displays
+	}
+
+	out := make([]interface{}, 0)
+	for i, display := range displays {
+		if locales[i] == locale {
+			out = append(out, display)
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,219 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-library/model/credential"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/config"
+)
+
+func TestLocalizerMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		accept  string
+		locales []string
+		want    string
+		wantOK  bool
+	}{
+		{name: "exact", accept: "de", locales: []string{"en", "de"}, want: "de", wantOK: true},
+		{name: "q-values", accept: "en;q=0.5, de;q=0.9", locales: []string{"en", "de"}, want: "de", wantOK: true},
+		{name: "q-values in any order", accept: "de;q=0.1, en", locales: []string{"de", "en"}, want: "en", wantOK: true},
+		{name: "regional variant", accept: "de-AT", locales: []string{"en", "de"}, want: "de", wantOK: true},
+		{name: "regional locale", accept: "de-AT", locales: []string{"en-US", "de-DE"}, want: "de-DE", wantOK: true},
+		{name: "fallback language", accept: "fr, de;q=0.5", locales: []string{"en", "de"}, want: "de", wantOK: true},
+		{name: "no match", accept: "fr", locales: []string{"en", "de"}},
+		{name: "no locales", accept: "de"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accepted, _, err := language.ParseAcceptLanguage(tt.accept)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			l := &localizer{accepted: accepted, selected: make(map[string]bool)}
+
+			got, ok := l.match(tt.locales)
+			if ok != tt.wantOK || got != tt.want {
+				t.Fatalf("expected %q (%v), got %q (%v)", tt.want, tt.wantOK, got, ok)
+			}
+
+			if tt.wantOK && !l.selected[tt.want] {
+				t.Fatalf("expected %s to be selected, got %v", tt.want, l.selected)
+			}
+		})
+	}
+}
+
+func TestIssuerMetadataLocalization(t *testing.T) {
+	metadata := credential.IssuerMetadata{
+		CredentialIssuer: "https://issuer.example",
+		Display: []credential.LocalizedCredential{
+			{Name: "Issuer", Locale: "en"},
+			{Name: "Aussteller", Locale: "de"},
+		},
+		CredentialConfigurationsSupported: map[string]credential.CredentialConfiguration{
+			"pid": {
+				Format: "vc+sd-jwt",
+				Display: []credential.LocalizedCredential{
+					{Name: "ID", Locale: "en-US"},
+					{Name: "Ausweis", Locale: "de-DE"},
+				},
+				Claims: map[string]interface{}{
+					"address": map[string]interface{}{
+						"display": []interface{}{
+							map[string]interface{}{"name": "Address", "locale": "en"},
+							map[string]interface{}{"name": "Adresse", "locale": "de"},
+						},
+					},
+				},
+			},
+			"ldp": {
+				Format: "ldp_vc",
+				// without german display, all entries are kept
+				Display: []credential.LocalizedCredential{
+					{Name: "Diploma", Locale: "en"},
+					{Name: "Diplôme", Locale: "fr"},
+				},
+				CredentialDefinition: credential.CredentialDefinition{
+					CredentialSubject: map[string]credential.CredentialSubject{
+						"degree": {Display: []credential.Display{{Name: "Degree", Locale: "en"}, {Name: "Abschluss", Locale: "de"}}},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name                string
+		localize            bool
+		accept              string
+		wantIssuer          []string
+		wantPid             []string
+		wantLdp             []string
+		wantClaim           []string
+		wantSubject         []string
+		wantContentLanguage string
+	}{
+		{
+			name:        "spec-complete by default",
+			accept:      "de",
+			wantIssuer:  []string{"en", "de"},
+			wantPid:     []string{"en-US", "de-DE"},
+			wantLdp:     []string{"en", "fr"},
+			wantClaim:   []string{"en", "de"},
+			wantSubject: []string{"en", "de"},
+		},
+		{
+			name:        "without Accept-Language",
+			localize:    true,
+			wantIssuer:  []string{"en", "de"},
+			wantPid:     []string{"en-US", "de-DE"},
+			wantLdp:     []string{"en", "fr"},
+			wantClaim:   []string{"en", "de"},
+			wantSubject: []string{"en", "de"},
+		},
+		{
+			name:                "localized",
+			localize:            true,
+			accept:              "de-AT, en;q=0.5",
+			wantIssuer:          []string{"de"},
+			wantPid:             []string{"de-DE"},
+			wantLdp:             []string{"en"},
+			wantClaim:           []string{"de"},
+			wantSubject:         []string{"de"},
+			wantContentLanguage: "de, de-DE, en",
+		},
+		{
+			name:        "no matching locale",
+			localize:    true,
+			accept:      "es",
+			wantIssuer:  []string{"en", "de"},
+			wantPid:     []string{"en-US", "de-DE"},
+			wantLdp:     []string{"en", "fr"},
+			wantClaim:   []string{"en", "de"},
+			wantSubject: []string{"en", "de"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			imp := &metadataImporter{metadata: metadata}
+			gw := NewGateway(config.GatewayConfig{PublicURL: "https://wellknown.example", LocalizeDisplay: tt.localize}, imp, nil)
+
+			router := gin.New()
+			router.GET("/v1/tenants/:tenantId/.well-known/openid-credential-issuer", gw.WellKnownCredentialIssuerHandler)
+
+			request := httptest.NewRequest(http.MethodGet, "/v1/tenants/tenant/.well-known/openid-credential-issuer", nil)
+			if tt.accept != "" {
+				request.Header.Set("Accept-Language", tt.accept)
+			}
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d", recorder.Code)
+			}
+
+			var got credential.IssuerMetadata
+			if err := json.Unmarshal(recorder.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+
+			claimDisplays := got.CredentialConfigurationsSupported["pid"].Claims["address"].(map[string]interface{})["display"].([]interface{})
+			claimLocales := make([]string, len(claimDisplays))
+			for i, display := range claimDisplays {
+				claimLocales[i] = display.(map[string]interface{})["locale"].(string)
+			}
+
+			subjectLocales := make([]string, 0)
+			for _, display := range got.CredentialConfigurationsSupported["ldp"].CredentialDefinition.CredentialSubject["degree"].Display {
+				subjectLocales = append(subjectLocales, display.Locale)
+			}
+
+			for _, check := range []struct {
+				name string
+				got  []string
+				want []string
+			}{
+				{name: "issuer display", got: localizedLocales(got.Display), want: tt.wantIssuer},
+				{name: "pid display", got: localizedLocales(got.CredentialConfigurationsSupported["pid"].Display), want: tt.wantPid},
+				{name: "ldp display", got: localizedLocales(got.CredentialConfigurationsSupported["ldp"].Display), want: tt.wantLdp},
+				{name: "claim display", got: claimLocales, want: tt.wantClaim},
+				{name: "credential subject display", got: subjectLocales, want: tt.wantSubject},
+			} {
+				if !reflect.DeepEqual(check.got, check.want) {
+					t.Fatalf("expected %s %v, got %v", check.name, check.want, check.got)
+				}
+			}
+
+			if got := recorder.Header().Get("Content-Language"); got != tt.wantContentLanguage {
+				t.Fatalf("expected Content-Language %q, got %q", tt.wantContentLanguage, got)
+			}
+
+			// the importer may share the metadata between requests
+			if len(metadata.Display) != 2 || len(metadata.CredentialConfigurationsSupported["pid"].Display) != 2 {
+				t.Fatal("expected the metadata of the importer to be unchanged")
+			}
+		})
+	}
+}
+
+func localizedLocales(displays []credential.LocalizedCredential) []string {
+	locales := make([]string, len(displays))
+	for i, display := range displays {
+		locales[i] = display.Locale
+	}
+	return locales
+}
@@ -0,0 +1,68 @@
+package rest
+
+import (
+	"strconv"
+	"strings"
+)
+
+// acceptQuality returns the quality the Accept header assigns to the media type. The most specific media
+// range wins (RFC 9110, section 12.5.1); a missing header accepts everything.
+func acceptQuality(accept, mediaType string) float64 {
+	if strings.TrimSpace(accept) == "" {
+		return 1
+	}
+
+	mainType, _, _ := strings.Cut(mediaType, "/")
+
+	quality, specificity := 0.0, -1
+	for _, mediaRange := range strings.Split(accept, ",") {
+		params := strings.Split(mediaRange, ";")
+		name := strings.ToLower(strings.TrimSpace(params[0]))
+
+		var level int
+		switch name {
+		case mediaType:
+			level = 2
+		case mainType + "/*":
+			level = 1
+		case "*/*":
+			level = 0
+		default:
+			continue
+		}
+
+		if level <= specificity {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range params[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(key, "q") {
+				parsed, err := strconv.ParseFloat(value, 64)
+				if err != nil || parsed < 0 || parsed > 1 {
+					parsed = 0
+				}
+				q = parsed
+			}
+		}
+
+		quality, specificity = q, level
+	}
+
+	return quality
+}
+
+// negotiateFormat selects the offered media type with the highest quality. Ties go to the earlier offer. If
+// the client accepts none of the offers, the first one is returned, as serving a representation is more
+// useful than 406 for clients like browsers (RFC 9110, section 12.5.1).
+func negotiateFormat(accept string, offers ...string) string {
+	best, bestQuality := offers[0], 0.0
+	for _, offer := range offers {
+		if q := acceptQuality(accept, offer); q > bestQuality {
+			best, bestQuality = offer, q
+		}
+	}
+
+	return best
+}
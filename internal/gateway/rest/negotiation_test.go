@@ -0,0 +1,31 @@
+package rest
+
+import "testing"
+
+func TestNegotiateFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		accept string
+		offers []string
+		want   string
+	}{
+		{name: "no header", accept: "", offers: []string{"application/json", "application/jwt"}, want: "application/json"},
+		{name: "jwt", accept: "application/jwt", offers: []string{"application/json", "application/jwt"}, want: "application/jwt"},
+		{name: "q-values", accept: "application/json;q=0.5, application/jwt", offers: []string{"application/json", "application/jwt"}, want: "application/jwt"},
+		{name: "q-values prefer json", accept: "application/jwt;q=0.1, application/json;q=0.9", offers: []string{"application/json", "application/jwt"}, want: "application/json"},
+		{name: "specific range wins", accept: "application/*;q=0.2, application/jwt;q=0.8", offers: []string{"application/json", "application/jwt"}, want: "application/jwt"},
+		{name: "excluded by q=0", accept: "application/jwt;q=0, */*", offers: []string{"application/json", "application/jwt"}, want: "application/json"},
+		{name: "tie goes to first offer", accept: "*/*", offers: []string{"application/json", "application/jwt"}, want: "application/json"},
+		{name: "html falls back to json", accept: "text/html", offers: []string{"application/json", "application/jwt"}, want: "application/json"},
+		{name: "jwt without signer", accept: "application/jwt", offers: []string{"application/json"}, want: "application/json"},
+		{name: "invalid q", accept: "application/jwt;q=x, application/json;q=0.1", offers: []string{"application/json", "application/jwt"}, want: "application/json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := negotiateFormat(tt.accept, tt.offers...); got != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
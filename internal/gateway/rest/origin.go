@@ -0,0 +1,121 @@
+package rest
+
+import (
+	"errors"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/common"
+)
+
+// errInvalidOrigin is returned for requests whose Host (or trusted X-Forwarded-Host) is not a plain host name
+var errInvalidOrigin = errors.New("invalid host")
+
+// validHost matches a DNS name, an IPv4 or a bracketed IPv6 address with optional port. Anything else could
+// break out of the JSON and YAML the origin is rendered into.
+var validHost = regexp.MustCompile(`^(\[[0-9A-Fa-f:.]+\]|[A-Za-z0-9]([A-Za-z0-9.-]*[A-Za-z0-9])?)(:[0-9]{1,5})?$`)
+
+// requestBaseURL returns the public URL of the tenant route group the request was sent to
+func (gw Gateway) requestBaseURL(c *gin.Context) (string, error) {
+	path := c.Request.URL.EscapedPath()
+	if i := strings.Index(path, common.BasePath); i >= 0 {
+		path = path[:i]
+	}
+	path = strings.TrimRight(path, "/")
+
+	if gw.conf.PublicURL != "" {
+		return strings.TrimRight(gw.conf.PublicURL, "/") + path, nil
+	}
+
+	origin, err := gw.requestOrigin(c)
+	if err != nil {
+		return "", err
+	}
+
+	return origin + path, nil
+}
+
+// requestOrigin returns the scheme and host of the configured public URL or, without it, of the request as
+// seen by the client. Forwarding headers are only honored from trusted proxies.
+func (gw Gateway) requestOrigin(c *gin.Context) (string, error) {
+	if gw.conf.PublicURL != "" {
+		// validated by the config
+		public, _ := url.Parse(gw.conf.PublicURL)
+		return public.Scheme + "://" + public.Host, nil
+	}
+
+	// the response depends on these headers, shared caches must not serve it for other hosts
+	varyOn(c, "Host")
+
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	host := c.Request.Host
+
+	if gw.fromTrustedProxy(c) {
+		varyOn(c, "X-Forwarded-Proto", "X-Forwarded-Host")
+
+		if proto := firstValue(c.GetHeader("X-Forwarded-Proto")); proto == "http" || proto == "https" {
+			scheme = proto
+		}
+		if forwarded := firstValue(c.GetHeader("X-Forwarded-Host")); forwarded != "" {
+			host = forwarded
+		}
+	}
+
+	if !validHost.MatchString(host) {
+		return "", errInvalidOrigin
+	}
+
+	return scheme + "://" + host, nil
+}
+
+// fromTrustedProxy reports, whether the request was sent by one of the trusted proxies
+func (gw Gateway) fromTrustedProxy(c *gin.Context) bool {
+	if len(gw.trustedProxies) == 0 {
+		return false
+	}
+
+	addrPort, err := netip.ParseAddrPort(c.Request.RemoteAddr)
+	if err != nil {
+		return false
+	}
+
+	addr := addrPort.Addr().Unmap()
+	for _, prefix := range gw.trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func firstValue(header string) string {
+	return strings.TrimSpace(strings.Split(header, ",")[0])
+}
+
+// varyOn adds the header names to Vary, unless they are already listed
+func varyOn(c *gin.Context, names ...string) {
+	header := c.Writer.Header()
+
+	for _, name := range names {
+		listed := false
+		for _, value := range header.Values("Vary") {
+			for _, existing := range strings.Split(value, ",") {
+				if strings.EqualFold(strings.TrimSpace(existing), name) {
+					listed = true
+				}
+			}
+		}
+
+		if !listed {
+			header.Add("Vary", name)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/config"
+)
+
+func TestRequestBaseURL(t *testing.T) {
+	tests := []struct {
+		name       string
+		conf       config.GatewayConfig
+		remoteAddr string
+		host       string
+		headers    map[string]string
+		want       string
+		wantVary   []string
+		wantErr    error
+	}{
+		{
+			name:       "request host",
+			remoteAddr: "192.0.2.1:1234",
+			host:       "issuer.example:8080",
+			want:       "http://issuer.example:8080/v1/tenants/t1",
+			wantVary:   []string{"Host"},
+		},
+		{
+			name:       "forwarded headers of untrusted client",
+			remoteAddr: "192.0.2.1:1234",
+			host:       "issuer.example",
+			headers:    map[string]string{"X-Forwarded-Host": "evil.example", "X-Forwarded-Proto": "https"},
+			want:       "http://issuer.example/v1/tenants/t1",
+			wantVary:   []string{"Host"},
+		},
+		{
+			name:       "forwarded headers of trusted proxy",
+			conf:       config.GatewayConfig{TrustedProxies: []string{"10.0.0.0/8"}},
+			remoteAddr: "10.1.2.3:1234",
+			host:       "wellknown:8080",
+			headers:    map[string]string{"X-Forwarded-Host": "issuer.example, proxy", "X-Forwarded-Proto": "https"},
+			want:       "https://issuer.example/v1/tenants/t1",
+			wantVary:   []string{"Host", "X-Forwarded-Proto", "X-Forwarded-Host"},
+		},
+		{
+			name:       "invalid forwarded proto",
+			conf:       config.GatewayConfig{TrustedProxies: []string{"10.1.2.3"}},
+			remoteAddr: "10.1.2.3:1234",
+			host:       "issuer.example",
+			headers:    map[string]string{"X-Forwarded-Proto": "javascript"},
+			want:       "http://issuer.example/v1/tenants/t1",
+			wantVary:   []string{"Host", "X-Forwarded-Proto", "X-Forwarded-Host"},
+		},
+		{
+			name:       "invalid forwarded host",
+			conf:       config.GatewayConfig{TrustedProxies: []string{"10.1.2.3"}},
+			remoteAddr: "10.1.2.3:1234",
+			host:       "issuer.example",
+			headers:    map[string]string{"X-Forwarded-Host": `issuer.example","x":"`},
+			wantErr:    errInvalidOrigin,
+		},
+		{
+			name:       "ipv6 host",
+			remoteAddr: "[2001:db8::1]:1234",
+			host:       "[2001:db8::2]:8080",
+			want:       "http://[2001:db8::2]:8080/v1/tenants/t1",
+			wantVary:   []string{"Host"},
+		},
+		{
+			name:       "public url",
+			conf:       config.GatewayConfig{PublicURL: "https://issuer.example/wellknown/", TrustedProxies: []string{"10.0.0.0/8"}},
+			remoteAddr: "10.1.2.3:1234",
+			host:       "evil.example",
+			headers:    map[string]string{"X-Forwarded-Host": "evil.example"},
+			want:       "https://issuer.example/wellknown/v1/tenants/t1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gw := NewGateway(tt.conf, nil, nil)
+
+			recorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(recorder)
+			c.Request = httptest.NewRequest(http.MethodGet, "/v1/tenants/t1/.well-known/openid-credential-issuer", nil)
+			c.Request.RemoteAddr = tt.remoteAddr
+			c.Request.Host = tt.host
+			for key, value := range tt.headers {
+				c.Request.Header.Set(key, value)
+			}
+
+			got, err := gw.requestBaseURL(c)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if got != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+
+			// resolving the origin twice must not repeat the Vary entries
+			_, _ = gw.requestOrigin(c)
+			if tt.wantErr == nil {
+				vary := recorder.Header().Values("Vary")
+				if len(vary) != len(tt.wantVary) {
+					t.Fatalf("expected Vary %v, got %v", tt.wantVary, vary)
+				}
+				for i := range vary {
+					if vary[i] != tt.wantVary[i] {
+						t.Fatalf("expected Vary %v, got %v", tt.wantVary, vary)
+					}
+				}
+			}
+		})
+	}
+}
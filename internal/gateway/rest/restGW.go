@@ -1,28 +1,53 @@
 package rest
 
 import (
+	"context"
 	"errors"
 	"net/http"
+	"net/netip"
+	"time"
 
 	ctxPkg "github.com/eclipse-xfsc/microservice-core-go/pkg/ctx"
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/logr"
 	"github.com/eclipse-xfsc/oid4-vci-vp-library/model/credential"
+	"github.com/eclipse-xfsc/oid4-vci-vp-library/model/oauth"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/config"
 	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/importer"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/signer"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/types"
 )
 
+const mimeJWT = "application/jwt"
+
 type Gateway struct {
-	conf config.GatewayConfig
-	imp  importer.Importer
+	conf           config.GatewayConfig
+	imp            importer.Importer
+	signer         *signer.Signer
+	signed         *signer.Cache
+	trustedProxies []netip.Prefix
 }
 
-func NewGateway(conf config.GatewayConfig, imp importer.Importer) Gateway {
-	return Gateway{
-		conf: conf,
-		imp:  imp,
+// NewGateway creates the REST gateway. The signer is optional; without it, the credential issuer metadata is
+// only served as JSON.
+func NewGateway(conf config.GatewayConfig, imp importer.Importer, metadataSigner *signer.Signer) Gateway {
+	// validated by the config
+	trustedProxies, _ := conf.TrustedProxyPrefixes()
+
+	gw := Gateway{
+		conf:           conf,
+		imp:            imp,
+		trustedProxies: trustedProxies,
+	}
+
+	if metadataSigner != nil {
+		gw.signer = metadataSigner
+		gw.signed = signer.NewCache(metadataSigner)
 	}
+
+	return gw
 }
 
 func (gw Gateway) enrichCredentialIssuerMetadataFromHeaders(
@@ -69,6 +94,68 @@ func (gw Gateway) enrichCredentialIssuerMetadataFromHeaders(
 	}
 }
 
+// varyOnEnrichment lists the configured enrichment headers of the credential issuer metadata in Vary
+func (gw Gateway) varyOnEnrichment(c *gin.Context) {
+	for _, key := range []string{
+		gw.conf.CredentialIssuerHeaderKey,
+		gw.conf.AuthorizationServerHeaderKey,
+		gw.conf.CredentialEndpointHeaderKey,
+		gw.conf.BatchCredentialEndpointHeaderKey,
+		gw.conf.DeferredCredentialEndpointHeaderKey,
+		gw.conf.NotificationEndpointHeaderKey,
+	} {
+		if key != "" {
+			varyOn(c, key)
+		}
+	}
+}
+
+func (gw Gateway) enrichAuthorizationServerMetadataFromHeaders(
+	c *gin.Context,
+	metadata *types.AuthorizationServerMetadata,
+) {
+	if key := gw.conf.AuthorizationServerHeaderKey; key != "" {
+		if value := c.GetHeader(key); value != "" {
+			metadata.Issuer = value
+		}
+	}
+
+	if key := gw.conf.AuthorizationEndpointHeaderKey; key != "" {
+		if value := c.GetHeader(key); value != "" {
+			metadata.AuthorizationEndpoint = value
+		}
+	}
+
+	if key := gw.conf.TokenEndpointHeaderKey; key != "" {
+		if value := c.GetHeader(key); value != "" {
+			metadata.TokenEndpoint = value
+		}
+	}
+}
+
+func (gw Gateway) enrichOpenIDConfigurationFromHeaders(
+	c *gin.Context,
+	configuration *oauth.OpenIdConfiguration,
+) {
+	if key := gw.conf.AuthorizationServerHeaderKey; key != "" {
+		if value := c.GetHeader(key); value != "" {
+			configuration.Issuer = value
+		}
+	}
+
+	if key := gw.conf.AuthorizationEndpointHeaderKey; key != "" {
+		if value := c.GetHeader(key); value != "" {
+			configuration.Authorization_Endpoint = value
+		}
+	}
+
+	if key := gw.conf.TokenEndpointHeaderKey; key != "" {
+		if value := c.GetHeader(key); value != "" {
+			configuration.Token_Endpoint = value
+		}
+	}
+}
+
 func appendUnique(values []string, value string) []string {
 	for _, existing := range values {
 		if existing == value {
@@ -89,22 +176,275 @@ func (gw Gateway) WellKnownCredentialIssuerHandler(c *gin.Context) {
 	tenantId := c.Param("tenantId")
 	if tenantId == "" {
 		c.JSON(404, "Not found.")
+		return
+	}
+
+	c.Writer.Header().Add("Vary", "Accept")
+	gw.varyOnEnrichment(c)
+
+	ctx, err := gw.templateContext(c, tenantId)
+	if err != nil {
+		abortWithImporterError(c, log, err)
+		return
+	}
+
+	localizer := gw.newLocalizer(c)
+
+	lastModified := gw.issuerLastModified(c, tenantId)
+	if gw.notModifiedSince(c, tenantId, lastModified) {
+		return
 	}
 
-	metadata, err := gw.imp.GetCredentialIssuerMetadata(c, tenantId)
+	metadata, err := gw.imp.GetCredentialIssuerMetadata(ctx, tenantId)
 
 	if err != nil {
-		status := http.StatusInternalServerError
-		if errors.Is(err, importer.ErrNotFound) {
-			status = http.StatusNotFound
+		abortWithImporterError(c, log, err)
+		return
+	}
+
+	imported, err := unsignedDigest(*metadata)
+	if err != nil {
+		abortWithImporterError(c, log, err)
+		return
+	}
+
+	gw.enrichCredentialIssuerMetadataFromHeaders(c, metadata)
+
+	if err := gw.rewriteLogos(c, tenantId, metadata); err != nil {
+		abortWithImporterError(c, log, err)
+		return
+	}
+
+	localizer.issuerMetadata(metadata)
+	localizer.setContentLanguage(c)
+
+	// the signed_metadata of the importer covers the imported metadata, not the one of this request
+	if err := gw.resignIfModified(c, tenantId, imported, metadata); err != nil {
+		abortWithImporterError(c, log, err)
+		return
+	}
+
+	offers := []string{gin.MIMEJSON}
+	if gw.signed != nil {
+		offers = append(offers, mimeJWT)
+	}
+
+	switch negotiateFormat(c.GetHeader("Accept"), offers...) {
+	case mimeJWT:
+		// signed after the enrichment, so the claims match the JSON representation of this request
+		jwt, err := gw.signed.Sign(c, tenantId, *metadata)
+		if err != nil {
+			abortWithImporterError(c, log, err)
+			return
 		}
 
-		if err := c.AbortWithError(status, err); err != nil {
-			log.Error(err, "failed to write status")
+		gw.respond(c, tenantId, lastModified, mimeJWT, []byte(jwt))
+	default:
+		gw.respondJSON(c, tenantId, lastModified, metadata)
+	}
+}
+
+func (gw Gateway) WellKnownAuthorizationServerHandler(c *gin.Context) {
+	log := ctxPkg.GetLogger(c)
+
+	tenantId := c.Param("tenantId")
+	if tenantId == "" {
+		c.JSON(404, "Not found.")
+		return
+	}
+
+	ctx, err := gw.templateContext(c, tenantId)
+	if err != nil {
+		abortWithImporterError(c, log, err)
+		return
+	}
+
+	metadata, err := gw.imp.GetAuthorizationServerMetadata(ctx, tenantId)
+
+	if err != nil {
+		abortWithImporterError(c, log, err)
+		return
+	}
+
+	gw.enrichAuthorizationServerMetadataFromHeaders(c, metadata)
+
+	gw.respondJSON(c, tenantId, time.Time{}, metadata)
+}
+
+func (gw Gateway) WellKnownOpenIDConfigurationHandler(c *gin.Context) {
+	log := ctxPkg.GetLogger(c)
+
+	tenantId := c.Param("tenantId")
+	if tenantId == "" {
+		c.JSON(404, "Not found.")
+		return
+	}
+
+	ctx, err := gw.templateContext(c, tenantId)
+	if err != nil {
+		abortWithImporterError(c, log, err)
+		return
+	}
+
+	configuration, err := gw.openIDConfiguration(ctx, tenantId)
+
+	if err != nil {
+		abortWithImporterError(c, log, err)
+		return
+	}
+
+	gw.enrichOpenIDConfigurationFromHeaders(c, configuration)
+
+	gw.respondJSON(c, tenantId, time.Time{}, configuration)
+}
+
+// openIDConfiguration returns the published discovery document of the tenant or, if there is none, derives it from
+// the Authorization Server Metadata. Without either the endpoints are unknown, so nothing is returned.
+func (gw Gateway) openIDConfiguration(ctx context.Context, tenantId string) (*oauth.OpenIdConfiguration, error) {
+	configuration, err := gw.imp.GetOpenIDConfiguration(ctx, tenantId)
+	if !errors.Is(err, importer.ErrNotFound) {
+		return configuration, err
+	}
+
+	metadata, err := gw.imp.GetAuthorizationServerMetadata(ctx, tenantId)
+	if err != nil {
+		return nil, err
+	}
+
+	return types.DeriveOpenIDConfiguration(*metadata), nil
+}
+
+func (gw Gateway) WellKnownVerifierHandler(c *gin.Context) {
+	log := ctxPkg.GetLogger(c)
+
+	tenantId := c.Param("tenantId")
+	if tenantId == "" {
+		c.JSON(404, "Not found.")
+		return
+	}
+
+	ctx, err := gw.templateContext(c, tenantId)
+	if err != nil {
+		abortWithImporterError(c, log, err)
+		return
+	}
+
+	metadata, err := gw.imp.GetVerifierMetadata(ctx, tenantId)
+
+	if err != nil {
+		abortWithImporterError(c, log, err)
+		return
+	}
+
+	gw.respondJSON(c, tenantId, time.Time{}, metadata)
+}
+
+// WellKnownJwksHandler serves the JSON Web Key Set of the tenant, whose key verifies signed_metadata and the
+// metadata served as JWT. The kid of the key is its thumbprint.
+func (gw Gateway) WellKnownJwksHandler(c *gin.Context) {
+	log := ctxPkg.GetLogger(c)
+
+	tenantId := c.Param("tenantId")
+	if tenantId == "" || gw.signer == nil {
+		c.JSON(404, "Not found.")
+		return
+	}
+
+	jwks, err := gw.signer.JWKS(c, tenantId)
+	if err != nil {
+		abortWithImporterError(c, log, err)
+		return
+	}
+
+	gw.respondJSON(c, tenantId, time.Time{}, jwks)
+}
+
+func (gw Gateway) WellKnownJwtVcIssuerHandler(c *gin.Context) {
+	log := ctxPkg.GetLogger(c)
+
+	tenantId := c.Param("tenantId")
+	if tenantId == "" {
+		c.JSON(404, "Not found.")
+		return
+	}
+
+	ctx, err := gw.templateContext(c, tenantId)
+	if err != nil {
+		abortWithImporterError(c, log, err)
+		return
+	}
+
+	metadata, err := gw.imp.GetJwtVcIssuerMetadata(ctx, tenantId)
+
+	if err != nil {
+		abortWithImporterError(c, log, err)
+		return
+	}
+
+	// the issuer has to match the iss claim of the issued SD-JWT VCs, which is the credential issuer
+	if key := gw.conf.CredentialIssuerHeaderKey; key != "" {
+		if value := c.GetHeader(key); value != "" {
+			metadata.Issuer = value
 		}
 	}
 
-	gw.enrichCredentialIssuerMetadataFromHeaders(c, metadata)
+	gw.respondJSON(c, tenantId, time.Time{}, metadata)
+}
 
-	c.JSON(200, metadata)
+// resignIfModified replaces the signed_metadata of the importer, if the metadata was changed after the import.
+// Without a signer the outdated signed_metadata is removed.
+func (gw Gateway) resignIfModified(c *gin.Context, tenantId, imported string, metadata *credential.IssuerMetadata) error {
+	if metadata.SignedMetadata == nil {
+		return nil
+	}
+
+	current, err := unsignedDigest(*metadata)
+	if err != nil || current == imported {
+		return err
+	}
+
+	if gw.signed == nil {
+		metadata.SignedMetadata = nil
+		return nil
+	}
+
+	return gw.signed.SignIssuerMetadata(c, tenantId, metadata)
+}
+
+func unsignedDigest(metadata credential.IssuerMetadata) (string, error) {
+	metadata.SignedMetadata = nil
+	return signer.Digest(metadata)
+}
+
+// templateContext passes the origin of the request to the importer, which renders the metadata templates with it
+func (gw Gateway) templateContext(c *gin.Context, tenantId string) (context.Context, error) {
+	origin, err := gw.requestOrigin(c)
+	if err != nil {
+		return nil, err
+	}
+
+	baseURL, err := gw.requestBaseURL(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return types.WithTemplateData(c, types.TemplateData{
+		Origin:   origin,
+		TenantId: tenantId,
+		BaseURL:  baseURL,
+	}), nil
+}
+
+func abortWithImporterError(c *gin.Context, log logr.Logger, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, importer.ErrNotFound), errors.Is(err, signer.ErrNoKey):
+		status = http.StatusNotFound
+	case errors.Is(err, errInvalidOrigin):
+		status = http.StatusBadRequest
+	}
+
+	if err := c.AbortWithError(status, err); err != nil {
+		log.Error(err, "failed to write status")
+	}
 }
@@ -0,0 +1,207 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-library/model/oauth"
+	"github.com/gin-gonic/gin"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/config"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/importer"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/types"
+)
+
+// discoveryImporter serves the optional discovery documents of a tenant
+type discoveryImporter struct {
+	importer.Importer
+
+	configuration       *oauth.OpenIdConfiguration
+	authorizationServer *types.AuthorizationServerMetadata
+}
+
+func (d discoveryImporter) GetOpenIDConfiguration(context.Context, string) (*oauth.OpenIdConfiguration, error) {
+	if d.configuration == nil {
+		return nil, importer.ErrNotFound
+	}
+
+	configuration := *d.configuration
+	return &configuration, nil
+}
+
+func (d discoveryImporter) GetAuthorizationServerMetadata(context.Context, string) (*types.AuthorizationServerMetadata, error) {
+	if d.authorizationServer == nil {
+		return nil, importer.ErrNotFound
+	}
+
+	metadata := *d.authorizationServer
+	return &metadata, nil
+}
+
+func TestWellKnownAuthorizationServerHandler(t *testing.T) {
+	keycloak := &types.AuthorizationServerMetadata{
+		Issuer:                 "https://auth.example/realms/issuer",
+		TokenEndpoint:          "https://auth.example/realms/issuer/protocol/openid-connect/token",
+		ResponseTypesSupported: []string{"code"},
+	}
+
+	headers := config.GatewayConfig{
+		AuthorizationServerHeaderKey: "X-Authorization-Server",
+		TokenEndpointHeaderKey:       "X-Token-Endpoint",
+	}
+
+	tests := []struct {
+		name                string
+		conf                config.GatewayConfig
+		authorizationServer *types.AuthorizationServerMetadata
+		header              http.Header
+		wantStatus          int
+		wantIssuer          string
+		wantTokenEndpoint   string
+	}{
+		{
+			name:                "published",
+			authorizationServer: keycloak,
+			wantStatus:          http.StatusOK,
+			wantIssuer:          keycloak.Issuer,
+			wantTokenEndpoint:   keycloak.TokenEndpoint,
+		},
+		{
+			name:                "overridden by headers",
+			conf:                headers,
+			authorizationServer: keycloak,
+			header: http.Header{
+				"X-Authorization-Server": {"https://proxy.example/auth"},
+				"X-Token-Endpoint":       {"https://proxy.example/auth/token"},
+			},
+			wantStatus:        http.StatusOK,
+			wantIssuer:        "https://proxy.example/auth",
+			wantTokenEndpoint: "https://proxy.example/auth/token",
+		},
+		{
+			name:                "headers without configured keys",
+			authorizationServer: keycloak,
+			header:              http.Header{"X-Authorization-Server": {"https://proxy.example/auth"}},
+			wantStatus:          http.StatusOK,
+			wantIssuer:          keycloak.Issuer,
+			wantTokenEndpoint:   keycloak.TokenEndpoint,
+		},
+		{name: "missing", conf: headers, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			imp := discoveryImporter{authorizationServer: tt.authorizationServer}
+			gw := NewGateway(tt.conf, imp, nil)
+
+			router := gin.New()
+			router.GET("/v1/tenants/:tenantId/.well-known/oauth-authorization-server", gw.WellKnownAuthorizationServerHandler)
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/tenants/tenant/.well-known/oauth-authorization-server", nil)
+			for key, values := range tt.header {
+				req.Header[key] = values
+			}
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, recorder.Code)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var got types.AuthorizationServerMetadata
+			if err := json.Unmarshal(recorder.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+
+			if got.Issuer != tt.wantIssuer || got.TokenEndpoint != tt.wantTokenEndpoint {
+				t.Fatalf("expected issuer %s and token endpoint %s, got %s and %s",
+					tt.wantIssuer, tt.wantTokenEndpoint, got.Issuer, got.TokenEndpoint)
+			}
+		})
+	}
+}
+
+func TestWellKnownOpenIDConfigurationHandler(t *testing.T) {
+	keycloak := &types.AuthorizationServerMetadata{
+		Issuer:                            "https://auth.example/realms/issuer",
+		AuthorizationEndpoint:             "https://auth.example/realms/issuer/protocol/openid-connect/auth",
+		TokenEndpoint:                     "https://auth.example/realms/issuer/protocol/openid-connect/token",
+		JwksUri:                           "https://auth.example/realms/issuer/protocol/openid-connect/certs",
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{string(oauth.PreAuthorizedCodeGrant)},
+		TokenEndpointAuthMethodsSupported: []string{"private_key_jwt"},
+	}
+
+	tests := []struct {
+		name                string
+		configuration       *oauth.OpenIdConfiguration
+		authorizationServer *types.AuthorizationServerMetadata
+		wantStatus          int
+		want                oauth.OpenIdConfiguration
+	}{
+		{
+			name:                "published",
+			configuration:       &oauth.OpenIdConfiguration{Issuer: "https://op.example", Token_Endpoint: "https://op.example/oauth2/token"},
+			authorizationServer: keycloak,
+			wantStatus:          http.StatusOK,
+			want:                oauth.OpenIdConfiguration{Issuer: "https://op.example", Token_Endpoint: "https://op.example/oauth2/token"},
+		},
+		{
+			name:                "derived from the authorization server",
+			authorizationServer: keycloak,
+			wantStatus:          http.StatusOK,
+			want: oauth.OpenIdConfiguration{
+				Issuer:                                keycloak.Issuer,
+				Authorization_Endpoint:                keycloak.AuthorizationEndpoint,
+				Token_Endpoint:                        keycloak.TokenEndpoint,
+				Jwks_Uri:                              keycloak.JwksUri,
+				Response_Types_Supported:              keycloak.ResponseTypesSupported,
+				Grant_Types_Supported:                 keycloak.GrantTypesSupported,
+				Token_Endpoint_Auth_Methods_Supported: keycloak.TokenEndpointAuthMethodsSupported,
+			},
+		},
+		{name: "neither", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			imp := discoveryImporter{configuration: tt.configuration, authorizationServer: tt.authorizationServer}
+			gw := NewGateway(config.GatewayConfig{PublicURL: "https://wellknown.example"}, imp, nil)
+
+			router := gin.New()
+			router.GET("/v1/tenants/:tenantId/.well-known/openid-configuration", gw.WellKnownOpenIDConfigurationHandler)
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/v1/tenants/tenant/.well-known/openid-configuration", nil))
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, recorder.Code)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var got oauth.OpenIdConfiguration
+			if err := json.Unmarshal(recorder.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+
+			gotJSON, _ := json.Marshal(got)
+			wantJSON, _ := json.Marshal(tt.want)
+			if string(gotJSON) != string(wantJSON) {
+				t.Fatalf("expected %s, got %s", wantJSON, gotJSON)
+			}
+		})
+	}
+}
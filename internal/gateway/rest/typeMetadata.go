@@ -0,0 +1,140 @@
+package rest
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	ctxPkg "github.com/eclipse-xfsc/microservice-core-go/pkg/ctx"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/common"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/importer"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/types"
+)
+
+const typeSchemaPath = "/vct-schema"
+
+// WellKnownTypeMetadataHandler serves the SD-JWT VC Type Metadata of a vct advertised in the credential issuer
+// metadata. The vct is referenced by the path parameter "vct" (see types.FindConfigurationByVct).
+func (gw Gateway) WellKnownTypeMetadataHandler(c *gin.Context) {
+	log := ctxPkg.GetLogger(c)
+
+	tenantId := c.Param("tenantId")
+	if tenantId == "" {
+		c.JSON(404, "Not found.")
+		return
+	}
+
+	ctx, err := gw.templateContext(c, tenantId)
+	if err != nil {
+		abortWithImporterError(c, log, err)
+		return
+	}
+
+	localizer := gw.newLocalizer(c)
+
+	lastModified := gw.issuerLastModified(c, tenantId)
+	if gw.notModifiedSince(c, tenantId, lastModified) {
+		return
+	}
+
+	metadata, err := gw.imp.GetCredentialIssuerMetadata(ctx, tenantId)
+	if err != nil {
+		abortWithImporterError(c, log, err)
+		return
+	}
+
+	if err := gw.rewriteLogos(c, tenantId, metadata); err != nil {
+		abortWithImporterError(c, log, err)
+		return
+	}
+
+	id, configuration, err := types.FindConfigurationByVct(metadata.CredentialConfigurationsSupported, c.Param("vct"))
+	if errors.Is(err, types.ErrAmbiguousReference) {
+		c.AbortWithStatusJSON(http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		abortWithImporterError(c, log, importer.ErrNotFound)
+		return
+	}
+
+	typeMetadata := types.NewTypeMetadata(localizer.configuration(*configuration))
+	localizer.setContentLanguage(c)
+
+	if gw.conf.TypeMetadataSchemaByReference && len(typeMetadata.Schema) > 0 {
+		schema, err := json.Marshal(typeMetadata.Schema)
+		if err != nil {
+			abortWithImporterError(c, log, err)
+			return
+		}
+
+		baseURL, err := gw.requestBaseURL(c)
+		if err != nil {
+			abortWithImporterError(c, log, err)
+			return
+		}
+
+		typeMetadata.Schema = nil
+		typeMetadata.SchemaUri = baseURL + common.BasePath + typeSchemaPath + "/" + url.PathEscape(id)
+		typeMetadata.SchemaUriIntegrity = integrity(schema)
+	}
+
+	gw.respondJSON(c, tenantId, lastModified, typeMetadata)
+}
+
+// WellKnownTypeSchemaHandler serves the JSON schema of a credential configuration which is referenced by the
+// schema_uri of its type metadata
+func (gw Gateway) WellKnownTypeSchemaHandler(c *gin.Context) {
+	log := ctxPkg.GetLogger(c)
+
+	tenantId := c.Param("tenantId")
+	if tenantId == "" {
+		c.JSON(404, "Not found.")
+		return
+	}
+
+	ctx, err := gw.templateContext(c, tenantId)
+	if err != nil {
+		abortWithImporterError(c, log, err)
+		return
+	}
+
+	lastModified := gw.issuerLastModified(c, tenantId)
+	if gw.notModifiedSince(c, tenantId, lastModified) {
+		return
+	}
+
+	metadata, err := gw.imp.GetCredentialIssuerMetadata(ctx, tenantId)
+	if err != nil {
+		abortWithImporterError(c, log, err)
+		return
+	}
+
+	configuration, ok := metadata.CredentialConfigurationsSupported[c.Param("configurationId")]
+	if !ok || len(configuration.Schema) == 0 {
+		abortWithImporterError(c, log, importer.ErrNotFound)
+		return
+	}
+
+	// the bytes have to be identical to the ones the integrity in the type metadata was calculated for
+	schema, err := json.Marshal(configuration.Schema)
+	if err != nil {
+		abortWithImporterError(c, log, fmt.Errorf("failed to encode schema: %w", err))
+		return
+	}
+
+	gw.respond(c, tenantId, lastModified, "application/schema+json", schema)
+}
+
+// integrity calculates a subresource integrity value (sha256) of the given content
+func integrity(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256-" + base64.StdEncoding.EncodeToString(sum[:])
+}
@@ -4,12 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"time"
 
 	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/importer"
 
 	messaging "github.com/eclipse-xfsc/nats-message-library"
 
 	"github.com/eclipse-xfsc/oid4-vci-vp-library/model/credential"
+	"github.com/eclipse-xfsc/oid4-vci-vp-library/model/oauth"
 
 	"github.com/eclipse-xfsc/microservice-core-go/pkg/logr"
 	"github.com/eclipse-xfsc/microservice-core-go/pkg/server"
@@ -18,41 +20,79 @@ import (
 	ce "github.com/eclipse-xfsc/cloud-event-provider"
 	"golang.org/x/sync/errgroup"
 
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/config"
 	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/common"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/database"
 	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/service"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/types"
 )
 
 type Importer struct {
-	stopChan chan bool
+	cancel    context.CancelFunc
+	solicitor *solicitor
 
 	svc        service.IssuerService
+	asSvc      service.AuthorizationServerService
+	vSvc       service.VerifierService
+	aSvc       service.AssetService
 	natsConfig ce.NatsConfig
 	log        logr.Logger
 }
 
 var _ importer.Importer = &Importer{}
 
-func NewImporter(svc service.IssuerService, natsConfig ce.NatsConfig, logger logr.Logger) *Importer {
+func NewImporter(
+	svc service.IssuerService,
+	asSvc service.AuthorizationServerService,
+	vSvc service.VerifierService,
+	aSvc service.AssetService,
+	natsConfig ce.NatsConfig,
+	solicitation config.SolicitationConfig,
+	logger logr.Logger,
+) *Importer {
+	var s *solicitor
+	if solicitation.Interval > 0 {
+		s = newSolicitor(svc, solicitation, natsConfig, logger)
+	}
+
 	return &Importer{
-		stopChan:   make(chan bool),
+		cancel:     func() {},
+		solicitor:  s,
 		svc:        svc,
+		asSvc:      asSvc,
+		vSvc:       vSvc,
+		aSvc:       aSvc,
 		natsConfig: natsConfig,
 		log:        logger,
 	}
 }
 
-func (b *Importer) Start(ctx context.Context, _ *server.Server, _ *common.Environment) error {
+func (b *Importer) Start(ctx context.Context, _ *server.Server, env *common.Environment) error {
+	ctx, b.cancel = context.WithCancel(ctx)
 	errGrp, ctx := errgroup.WithContext(ctx)
 
 	errGrp.Go(func() error {
 		return b.listen(ctx)
 	})
 
-	return nil
+	if b.solicitor == nil {
+		return nil
+	}
+
+	env.AddHealthDetail("solicitation", func() any {
+		return b.solicitor.Status()
+	})
+
+	return b.solicitor.start(ctx)
 }
 
 func (b *Importer) Stop() error {
-	b.stopChan <- true
+	b.cancel()
+
+	if b.solicitor != nil {
+		b.solicitor.stop()
+	}
+
 	return nil
 }
 
@@ -61,7 +101,71 @@ func (b *Importer) GotErrors() bool {
 }
 
 func (b *Importer) GetCredentialIssuerMetadata(ctx context.Context, tenantID string) (*credential.IssuerMetadata, error) {
-	return b.svc.GetIssuer(ctx, tenantID, false)
+	metadata, err := b.svc.GetIssuer(ctx, tenantID, false)
+	return metadata, translateError(err)
+}
+
+func (b *Importer) GetCredentialConfigurations(ctx context.Context, tenantID string) (map[string]credential.CredentialConfiguration, error) {
+	configurations, err := b.svc.ListConfigurations(ctx, tenantID, false)
+	return configurations, translateError(err)
+}
+
+func (b *Importer) GetCredentialIssuerLastModified(ctx context.Context, tenantID string) (time.Time, error) {
+	lastModified, err := b.svc.GetLastModified(ctx, tenantID)
+	if err != nil {
+		return time.Time{}, translateError(err)
+	}
+
+	// the metadata links the assets by their content
+	assetsModified, err := b.aSvc.GetAssetsLastModified(ctx, tenantID)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if assetsModified.After(lastModified) {
+		lastModified = assetsModified
+	}
+
+	return lastModified, nil
+}
+
+func (b *Importer) GetAuthorizationServerMetadata(ctx context.Context, tenantID string) (*types.AuthorizationServerMetadata, error) {
+	metadata, err := b.asSvc.GetAuthorizationServer(ctx, tenantID)
+	return metadata, translateError(err)
+}
+
+func (b *Importer) GetOpenIDConfiguration(ctx context.Context, tenantID string) (*oauth.OpenIdConfiguration, error) {
+	configuration, err := b.svc.GetOpenIDConfiguration(ctx, tenantID)
+	return configuration, translateError(err)
+}
+
+func (b *Importer) GetVerifierMetadata(ctx context.Context, tenantID string) (*types.VerifierMetadata, error) {
+	metadata, err := b.vSvc.GetVerifier(ctx, tenantID)
+	return metadata, translateError(err)
+}
+
+func (b *Importer) GetJwtVcIssuerMetadata(ctx context.Context, tenantID string) (*types.JwtVcIssuerMetadata, error) {
+	metadata, err := b.svc.GetJwtVcIssuer(ctx, tenantID)
+	return metadata, translateError(err)
+}
+
+func (b *Importer) ListAssets(ctx context.Context, tenantID string) (map[string]types.Asset, error) {
+	assets, err := b.aSvc.ListAssets(ctx, tenantID)
+	return assets, translateError(err)
+}
+
+func (b *Importer) GetAsset(ctx context.Context, tenantID, name string) (*types.Asset, error) {
+	asset, err := b.aSvc.GetAsset(ctx, tenantID, name)
+	return asset, translateError(err)
+}
+
+// translateError maps store errors to the errors defined by the importer package
+func translateError(err error) error {
+	if errors.Is(err, database.ErrNotFound) {
+		return importer.ErrNotFound
+	}
+
+	return err
 }
 
 func (b *Importer) listen(ctx context.Context) error {
@@ -77,11 +181,13 @@ func (b *Importer) listen(ctx context.Context) error {
 	}
 
 	b.log.Info("Subscribe on topic " + messaging.TopicIssuerRegistration)
-	for {
+	for ctx.Err() == nil {
 		if err := client.SubCtx(ctx, b.handleEvent); err != nil {
 			b.log.Error(err, "cloudEventProvider.Sub failed")
 		}
 	}
+
+	return ctx.Err()
 }
 
 // TODO: define events?!
@@ -92,6 +198,18 @@ func (b *Importer) handleEvent(e event.Event) {
 		b.handleIssuerEvent(context.TODO(), e.Data())
 	case messaging.EventTypeIssuerCredentialRegistration:
 		b.handleConfigurationEvent(context.TODO(), e.Data())
+	case types.EventTypeAuthorizationServerRegistration:
+		b.handleAuthorizationServerEvent(context.TODO(), e.Data())
+	case types.EventTypeOpenIDConfigurationRegistration:
+		b.handleOpenIDConfigurationEvent(context.TODO(), e.Data())
+	case types.EventTypeVerifierRegistration:
+		b.handleVerifierEvent(context.TODO(), e.Data())
+	case types.EventTypeJwtVcIssuerRegistration:
+		b.handleJwtVcIssuerEvent(context.TODO(), e.Data())
+	case types.EventTypeIssuerDeregistration:
+		b.handleIssuerDeregistrationEvent(context.TODO(), e.Data())
+	case types.EventTypeIssuerCredentialDeregistration:
+		b.handleConfigurationDeregistrationEvent(context.TODO(), e.Data())
 	default:
 		b.log.Info("received unknown event type", "type", e.Type())
 	}
@@ -110,7 +228,12 @@ func (b *Importer) handleConfigurationEvent(ctx context.Context, data []byte) {
 	}
 
 	if err := b.svc.UpsertConfiguration(ctx, msg.TenantId, msg.ConfigurationId, msg.CredentialConfiguration); err != nil {
-		b.log.Error(err, "failed to UpsertIssuer")
+		if errors.Is(err, service.ErrForeignRecord) {
+			b.log.Info("ignoring registration of credential configuration managed by another source", "tenant", msg.TenantId, "configuration", msg.ConfigurationId)
+			return
+		}
+
+		b.log.Error(err, "failed to UpsertConfiguration")
 	}
 }
 
@@ -127,6 +250,139 @@ func (b *Importer) handleIssuerEvent(ctx context.Context, data []byte) {
 	}
 
 	if err := b.svc.UpsertIssuer(ctx, msg.TenantId, msg.Issuer); err != nil {
+		if errors.Is(err, service.ErrForeignRecord) {
+			b.log.Info("ignoring registration of issuer managed by another source", "tenant", msg.TenantId)
+			return
+		}
+
 		b.log.Error(err, "failed to UpsertIssuer")
 	}
 }
+
+func (b *Importer) handleAuthorizationServerEvent(ctx context.Context, data []byte) {
+	var msg types.AuthorizationServerRegistration
+	if err := json.Unmarshal(data, &msg); err != nil {
+		b.log.Error(err, "failed to unmarshal authorization server")
+		return
+	}
+
+	if msg.TenantId == "" {
+		b.log.Error(errors.New("invalid request.message (empty tenantID)"), "msg", msg)
+		return
+	}
+
+	if err := b.asSvc.UpsertAuthorizationServer(ctx, msg.TenantId, msg.AuthorizationServer); err != nil {
+		b.log.Error(err, "failed to UpsertAuthorizationServer")
+	}
+}
+
+func (b *Importer) handleOpenIDConfigurationEvent(ctx context.Context, data []byte) {
+	var msg types.OpenIDConfigurationRegistration
+	if err := json.Unmarshal(data, &msg); err != nil {
+		b.log.Error(err, "failed to unmarshal openid configuration")
+		return
+	}
+
+	if msg.TenantId == "" {
+		b.log.Error(errors.New("invalid request.message (empty tenantID)"), "msg", msg)
+		return
+	}
+
+	if err := b.svc.UpsertOpenIDConfiguration(ctx, msg.TenantId, msg.OpenIDConfiguration); err != nil {
+		b.log.Error(err, "failed to UpsertOpenIDConfiguration")
+	}
+}
+
+func (b *Importer) handleVerifierEvent(ctx context.Context, data []byte) {
+	var msg types.VerifierRegistration
+	if err := json.Unmarshal(data, &msg); err != nil {
+		b.log.Error(err, "failed to unmarshal verifier")
+		return
+	}
+
+	if msg.TenantId == "" {
+		b.log.Error(errors.New("invalid request.message (empty tenantID)"), "msg", msg)
+		return
+	}
+
+	if err := b.vSvc.UpsertVerifier(ctx, msg.TenantId, msg.Verifier); err != nil {
+		b.log.Error(err, "failed to UpsertVerifier")
+	}
+}
+
+func (b *Importer) handleJwtVcIssuerEvent(ctx context.Context, data []byte) {
+	var msg types.JwtVcIssuerRegistration
+	if err := json.Unmarshal(data, &msg); err != nil {
+		b.log.Error(err, "failed to unmarshal jwt-vc-issuer")
+		return
+	}
+
+	if msg.TenantId == "" {
+		b.log.Error(errors.New("invalid request.message (empty tenantID)"), "msg", msg)
+		return
+	}
+
+	if err := b.svc.UpsertJwtVcIssuer(ctx, msg.TenantId, msg.JwtVcIssuer); err != nil {
+		b.log.Error(err, "failed to UpsertJwtVcIssuer")
+	}
+}
+
+func (b *Importer) handleIssuerDeregistrationEvent(ctx context.Context, data []byte) {
+	var msg types.IssuerDeregistration
+	if err := json.Unmarshal(data, &msg); err != nil {
+		b.log.Error(err, "failed to unmarshal issuer deregistration")
+		return
+	}
+
+	if msg.TenantId == "" {
+		b.log.Error(errors.New("invalid request.message (empty tenantID)"), "msg", msg)
+		return
+	}
+
+	if err := b.svc.DeleteIssuer(ctx, msg.TenantId); err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			b.log.Info("deregistered issuer does not exist", "tenant", msg.TenantId)
+			return
+		}
+
+		if errors.Is(err, service.ErrForeignRecord) {
+			b.log.Info("ignoring deregistration of issuer managed by another source", "tenant", msg.TenantId)
+			return
+		}
+
+		b.log.Error(err, "failed to DeleteIssuer")
+		return
+	}
+
+	b.log.Info("issuer deregistered", "tenant", msg.TenantId)
+}
+
+func (b *Importer) handleConfigurationDeregistrationEvent(ctx context.Context, data []byte) {
+	var msg types.CredentialDeregistration
+	if err := json.Unmarshal(data, &msg); err != nil {
+		b.log.Error(err, "failed to unmarshal credential deregistration")
+		return
+	}
+
+	if msg.TenantId == "" || msg.ConfigurationId == "" {
+		b.log.Error(errors.New("invalid request.message (empty tenantID or configurationId)"), "msg", msg)
+		return
+	}
+
+	if err := b.svc.DeleteConfiguration(ctx, msg.TenantId, msg.ConfigurationId); err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			b.log.Info("deregistered credential configuration does not exist", "tenant", msg.TenantId, "configuration", msg.ConfigurationId)
+			return
+		}
+
+		if errors.Is(err, service.ErrForeignRecord) {
+			b.log.Info("ignoring deregistration of credential configuration managed by another source", "tenant", msg.TenantId, "configuration", msg.ConfigurationId)
+			return
+		}
+
+		b.log.Error(err, "failed to DeleteConfiguration")
+		return
+	}
+
+	b.log.Info("credential configuration deregistered", "tenant", msg.TenantId, "configuration", msg.ConfigurationId)
+}
@@ -0,0 +1,193 @@
+package broadcast
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	ctxPkg "github.com/eclipse-xfsc/microservice-core-go/pkg/ctx"
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/logr"
+	"github.com/eclipse-xfsc/nats-message-library/common"
+	"github.com/google/uuid"
+	"github.com/madflojo/tasks"
+
+	messaging "github.com/eclipse-xfsc/nats-message-library"
+
+	ce "github.com/eclipse-xfsc/cloud-event-provider"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/config"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/service"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/types"
+)
+
+// solicitor periodically asks the plugins of all known tenants to announce their registrations again and
+// reconciles the stored records with the announcements received within the configured window
+type solicitor struct {
+	svc           service.IssuerService
+	conf          config.SolicitationConfig
+	natsConfig    ce.NatsConfig
+	log           logr.Logger
+	taskScheduler *tasks.Scheduler
+
+	mu     sync.RWMutex
+	status SolicitationStatus
+}
+
+// SolicitationStatus of the last solicitation round as exposed in the health output
+type SolicitationStatus struct {
+	Interval  string                   `json:"interval"`
+	Window    string                   `json:"window"`
+	LastRun   *time.Time               `json:"last_run,omitempty"`
+	Tenants   int                      `json:"tenants"`
+	Missing   []service.Reconciliation `json:"missing,omitempty"`
+	LastError string                   `json:"last_error,omitempty"`
+}
+
+func newSolicitor(svc service.IssuerService, conf config.SolicitationConfig, natsConfig ce.NatsConfig, logger logr.Logger) *solicitor {
+	return &solicitor{
+		svc:           svc,
+		conf:          conf,
+		natsConfig:    natsConfig,
+		log:           logger,
+		taskScheduler: tasks.New(),
+		status: SolicitationStatus{
+			Interval: conf.Interval.String(),
+			Window:   conf.Window.String(),
+		},
+	}
+}
+
+func (s *solicitor) start(ctx context.Context) error {
+	ctx = ctxPkg.WithLogger(ctx, s.log)
+
+	_, err := s.taskScheduler.Add(&tasks.Task{
+		TaskContext:       tasks.TaskContext{Context: ctx},
+		Interval:          s.conf.Interval,
+		RunSingleInstance: true,
+		FuncWithTaskContext: func(tc tasks.TaskContext) error {
+			return s.solicit(tc.Context)
+		},
+	})
+	if err != nil {
+		s.log.Error(err, "failed to create scheduler for solicitation")
+		return err
+	}
+
+	return nil
+}
+
+func (s *solicitor) stop() {
+	s.taskScheduler.Stop()
+}
+
+func (s *solicitor) Status() SolicitationStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.status
+}
+
+func (s *solicitor) solicit(ctx context.Context) error {
+	now := time.Now()
+
+	missing, tenants, err := s.round(ctx, now)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.status.LastRun = &now
+	s.status.Tenants = tenants
+	s.status.Missing = missing
+	s.status.LastError = ""
+
+	if err != nil {
+		s.status.LastError = err.Error()
+		return err
+	}
+
+	return nil
+}
+
+// round publishes a solicitation per tenant, waits for the window to pass and reconciles every tenant.
+// Registrations which were received after since count as announced.
+func (s *solicitor) round(ctx context.Context, since time.Time) ([]service.Reconciliation, int, error) {
+	tenants, err := s.svc.ListTenants(ctx)
+	if err != nil {
+		s.log.Error(err, "failed to list tenants for solicitation")
+		return nil, 0, err
+	}
+
+	if len(tenants) == 0 {
+		return nil, 0, nil
+	}
+
+	client, err := ce.New(
+		ce.Config{Protocol: ce.ProtocolTypeNats, Settings: s.natsConfig},
+		ce.ConnectionTypePub,
+		types.TopicIssuerSolicitation,
+	)
+	if err != nil {
+		s.log.Error(err, "failed to connect for solicitation")
+		return nil, len(tenants), err
+	}
+	defer client.Close()
+
+	replyUntil := since.Add(s.conf.Window)
+
+	for _, tenant := range tenants {
+		if err := s.publish(ctx, client, tenant, replyUntil); err != nil {
+			s.log.Error(err, "failed to publish solicitation", "tenant", tenant)
+			return nil, len(tenants), err
+		}
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, len(tenants), ctx.Err()
+	case <-time.After(time.Until(replyUntil)):
+	}
+
+	missing := make([]service.Reconciliation, 0)
+	for _, tenant := range tenants {
+		result, err := s.svc.Reconcile(ctx, tenant, since, s.conf.PruneMissing)
+		if err != nil {
+			s.log.Error(err, "failed to reconcile solicited registrations", "tenant", tenant)
+			return missing, len(tenants), err
+		}
+
+		if !result.IssuerMissing && len(result.MissingConfigurations) == 0 {
+			continue
+		}
+
+		s.log.Info("registrations missing after solicitation",
+			"tenant", tenant,
+			"issuerMissing", result.IssuerMissing,
+			"configurations", result.MissingConfigurations,
+			"pruned", result.Pruned,
+		)
+		missing = append(missing, *result)
+	}
+
+	return missing, len(tenants), nil
+}
+
+func (s *solicitor) publish(ctx context.Context, client *ce.CloudEventProviderClient, tenant string, replyUntil time.Time) error {
+	data, err := json.Marshal(types.IssuerSolicitation{
+		Request: common.Request{
+			TenantId:  tenant,
+			RequestId: uuid.NewString(),
+		},
+		ReplyUntil: replyUntil,
+	})
+	if err != nil {
+		return err
+	}
+
+	event, err := ce.NewEvent(messaging.SourceWellKnownService, types.EventTypeIssuerSolicitation, data)
+	if err != nil {
+		return err
+	}
+
+	return client.PubCtx(ctx, event)
+}
@@ -0,0 +1,242 @@
+package composite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-library/model/credential"
+	"github.com/eclipse-xfsc/oid4-vci-vp-library/model/oauth"
+
+	logPkg "github.com/eclipse-xfsc/microservice-core-go/pkg/logr"
+	serverPkg "github.com/eclipse-xfsc/microservice-core-go/pkg/server"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/config"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/common"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/importer"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/signer"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/types"
+)
+
+// Importer chains several importers. The issuer metadata is taken from the first importer of the issuer
+// precedence which knows the tenant; the credential configurations of all importers are merged, where
+// importers earlier in the configurations precedence win for the same configuration id.
+type Importer struct {
+	conf      config.CompositeConfig
+	importers map[string]importer.Importer
+	signed    *signer.Cache
+	log       logPkg.Logger
+}
+
+var _ importer.Importer = &Importer{}
+
+// NewImporter creates the composite importer of the given importers, keyed by their config name. The chained
+// importers must not sign the metadata themselves; if a signer is given, the merged metadata is signed instead.
+func NewImporter(
+	conf config.CompositeConfig,
+	importers map[string]importer.Importer,
+	metadataSigner *signer.Signer,
+	logger logPkg.Logger,
+) *Importer {
+	c := &Importer{
+		conf:      conf,
+		importers: importers,
+		log:       logger,
+	}
+
+	if metadataSigner != nil {
+		c.signed = signer.NewCache(metadataSigner)
+	}
+
+	return c
+}
+
+func (c *Importer) Start(ctx context.Context, server *serverPkg.Server, env *common.Environment) error {
+	for _, name := range c.conf.Importers {
+		if err := c.importers[name].Start(ctx, server, env); err != nil {
+			return fmt.Errorf("failed to start %s importer: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func (c *Importer) Stop() error {
+	var errs []error
+	for _, name := range c.conf.Importers {
+		if err := c.importers[name].Stop(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to stop %s importer: %w", name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Importer) GotErrors() bool {
+	for _, name := range c.conf.Importers {
+		if c.importers[name].GotErrors() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *Importer) GetCredentialIssuerMetadata(ctx context.Context, tenantID string) (*credential.IssuerMetadata, error) {
+	issuer, err := first(c, c.conf.Precedence(c.conf.IssuerPrecedence, tenantID), func(imp importer.Importer) (*credential.IssuerMetadata, error) {
+		return imp.GetCredentialIssuerMetadata(ctx, tenantID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	configurations, err := c.configurations(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	issuer.CredentialConfigurationsSupported = configurations
+
+	// a signature of a single importer does not cover the merged metadata
+	issuer.SignedMetadata = nil
+
+	if c.signed != nil {
+		if err := c.signed.SignIssuerMetadata(ctx, tenantID, issuer); err != nil {
+			return nil, err
+		}
+	}
+
+	return issuer, nil
+}
+
+// GetCredentialConfigurations returns the merged credential configurations of the importers
+func (c *Importer) GetCredentialConfigurations(ctx context.Context, tenantID string) (map[string]credential.CredentialConfiguration, error) {
+	return c.configurations(ctx, tenantID)
+}
+
+// configurations merges the credential configurations of the importers in the configurations precedence
+func (c *Importer) configurations(ctx context.Context, tenantID string) (map[string]credential.CredentialConfiguration, error) {
+	precedence := c.conf.Precedence(c.conf.ConfigurationsPrecedence, tenantID)
+
+	configurations := make(map[string]credential.CredentialConfiguration)
+	for i := len(precedence) - 1; i >= 0; i-- {
+		// importers without issuer for the tenant contribute their configurations as well
+		listed, err := c.importers[precedence[i]].GetCredentialConfigurations(ctx, tenantID)
+		if errors.Is(err, importer.ErrNotFound) {
+			continue
+		}
+
+		if err != nil {
+			c.log.Error(err, "failed to load credential configurations", "importer", precedence[i], "tenant", tenantID)
+			return nil, err
+		}
+
+		for id, configuration := range listed {
+			configurations[id] = configuration
+		}
+	}
+
+	return configurations, nil
+}
+
+// GetCredentialIssuerLastModified returns the latest modification of all importers which know the tenant
+func (c *Importer) GetCredentialIssuerLastModified(ctx context.Context, tenantID string) (time.Time, error) {
+	var lastModified time.Time
+	found := false
+
+	for _, name := range c.conf.Importers {
+		modified, err := c.importers[name].GetCredentialIssuerLastModified(ctx, tenantID)
+		if errors.Is(err, importer.ErrNotFound) {
+			continue
+		}
+
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		found = true
+		if modified.After(lastModified) {
+			lastModified = modified
+		}
+	}
+
+	if !found {
+		return time.Time{}, importer.ErrNotFound
+	}
+
+	return lastModified, nil
+}
+
+func (c *Importer) GetAuthorizationServerMetadata(ctx context.Context, tenantID string) (*types.AuthorizationServerMetadata, error) {
+	return first(c, c.conf.Precedence(c.conf.IssuerPrecedence, tenantID), func(imp importer.Importer) (*types.AuthorizationServerMetadata, error) {
+		return imp.GetAuthorizationServerMetadata(ctx, tenantID)
+	})
+}
+
+func (c *Importer) GetOpenIDConfiguration(ctx context.Context, tenantID string) (*oauth.OpenIdConfiguration, error) {
+	return first(c, c.conf.Precedence(c.conf.IssuerPrecedence, tenantID), func(imp importer.Importer) (*oauth.OpenIdConfiguration, error) {
+		return imp.GetOpenIDConfiguration(ctx, tenantID)
+	})
+}
+
+func (c *Importer) GetVerifierMetadata(ctx context.Context, tenantID string) (*types.VerifierMetadata, error) {
+	return first(c, c.conf.Precedence(c.conf.IssuerPrecedence, tenantID), func(imp importer.Importer) (*types.VerifierMetadata, error) {
+		return imp.GetVerifierMetadata(ctx, tenantID)
+	})
+}
+
+func (c *Importer) GetJwtVcIssuerMetadata(ctx context.Context, tenantID string) (*types.JwtVcIssuerMetadata, error) {
+	return first(c, c.conf.Precedence(c.conf.IssuerPrecedence, tenantID), func(imp importer.Importer) (*types.JwtVcIssuerMetadata, error) {
+		return imp.GetJwtVcIssuerMetadata(ctx, tenantID)
+	})
+}
+
+// ListAssets merges the assets of the importers in the issuer precedence, where importers earlier in the
+// precedence win for the same name
+func (c *Importer) ListAssets(ctx context.Context, tenantID string) (map[string]types.Asset, error) {
+	precedence := c.conf.Precedence(c.conf.IssuerPrecedence, tenantID)
+
+	assets := make(map[string]types.Asset)
+	for i := len(precedence) - 1; i >= 0; i-- {
+		listed, err := c.importers[precedence[i]].ListAssets(ctx, tenantID)
+		if errors.Is(err, importer.ErrNotFound) {
+			continue
+		}
+
+		if err != nil {
+			c.log.Error(err, "failed to list assets", "importer", precedence[i], "tenant", tenantID)
+			return nil, err
+		}
+
+		for name, asset := range listed {
+			assets[name] = asset
+		}
+	}
+
+	return assets, nil
+}
+
+func (c *Importer) GetAsset(ctx context.Context, tenantID, name string) (*types.Asset, error) {
+	return first(c, c.conf.Precedence(c.conf.IssuerPrecedence, tenantID), func(imp importer.Importer) (*types.Asset, error) {
+		return imp.GetAsset(ctx, tenantID, name)
+	})
+}
+
+// first returns the result of the first importer in the precedence which knows the tenant
+func first[T any](c *Importer, precedence []string, get func(imp importer.Importer) (*T, error)) (*T, error) {
+	for _, name := range precedence {
+		result, err := get(c.importers[name])
+		if errors.Is(err, importer.ErrNotFound) {
+			continue
+		}
+
+		if err != nil {
+			c.log.Error(err, "importer failed", "importer", name)
+			return nil, err
+		}
+
+		return result, nil
+	}
+
+	return nil, importer.ErrNotFound
+}
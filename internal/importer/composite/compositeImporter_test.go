@@ -0,0 +1,123 @@
+package composite
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/logr"
+	"github.com/eclipse-xfsc/oid4-vci-vp-library/model/credential"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/config"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/importer"
+)
+
+// fakeImporter serves fixed credential configurations, but no issuer
+type fakeImporter struct {
+	importer.Importer
+
+	configurations map[string]credential.CredentialConfiguration
+	err            error
+}
+
+func (f fakeImporter) GetCredentialIssuerMetadata(context.Context, string) (*credential.IssuerMetadata, error) {
+	return nil, importer.ErrNotFound
+}
+
+func (f fakeImporter) GetCredentialConfigurations(context.Context, string) (map[string]credential.CredentialConfiguration, error) {
+	return f.configurations, f.err
+}
+
+func configurations(formats map[string]string) map[string]credential.CredentialConfiguration {
+	out := make(map[string]credential.CredentialConfiguration, len(formats))
+	for id, format := range formats {
+		out[id] = credential.CredentialConfiguration{Format: format}
+	}
+
+	return out
+}
+
+func TestConfigurations(t *testing.T) {
+	logger, err := logr.New("error", false, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	failure := errors.New("database down")
+
+	importers := map[string]importer.Importer{
+		config.ImporterGit:       fakeImporter{configurations: configurations(map[string]string{"pid": "git", "mdl": "git"})},
+		config.ImporterBroadcast: fakeImporter{configurations: configurations(map[string]string{"pid": "broadcast", "health": "broadcast"})},
+		config.ImporterFile:      fakeImporter{err: importer.ErrNotFound},
+		config.ImporterMirror:    fakeImporter{err: failure},
+	}
+
+	tests := []struct {
+		name       string
+		importers  []string
+		precedence config.KeyValues
+		want       map[string]string
+		err        error
+	}{
+		{
+			name:      "earlier importer wins",
+			importers: []string{config.ImporterGit, config.ImporterBroadcast},
+			want:      map[string]string{"pid": "git", "mdl": "git", "health": "broadcast"},
+		},
+		{
+			name:      "default precedence",
+			importers: []string{config.ImporterBroadcast, config.ImporterGit},
+			want:      map[string]string{"pid": "broadcast", "mdl": "git", "health": "broadcast"},
+		},
+		{
+			name:       "tenant precedence",
+			importers:  []string{config.ImporterGit, config.ImporterBroadcast},
+			precedence: config.KeyValues{"tenant": "BROADCAST|GIT"},
+			want:       map[string]string{"pid": "broadcast", "mdl": "git", "health": "broadcast"},
+		},
+		{
+			name:       "other tenant precedence",
+			importers:  []string{config.ImporterGit, config.ImporterBroadcast},
+			precedence: config.KeyValues{"other": "BROADCAST|GIT"},
+			want:       map[string]string{"pid": "git", "mdl": "git", "health": "broadcast"},
+		},
+		{
+			name:      "unknown tenant is skipped",
+			importers: []string{config.ImporterFile, config.ImporterBroadcast},
+			want:      map[string]string{"pid": "broadcast", "health": "broadcast"},
+		},
+		{
+			name:      "failing importer",
+			importers: []string{config.ImporterGit, config.ImporterMirror},
+			err:       failure,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewImporter(config.CompositeConfig{
+				Importers:                tt.importers,
+				ConfigurationsPrecedence: tt.precedence,
+			}, importers, nil, *logger)
+
+			got, err := c.configurations(context.Background(), "tenant")
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+			if tt.err != nil {
+				return
+			}
+
+			formats := make(map[string]string, len(got))
+			for id, configuration := range got {
+				formats[id] = configuration.Format
+			}
+
+			if !reflect.DeepEqual(formats, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, formats)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	logPkg "github.com/eclipse-xfsc/microservice-core-go/pkg/logr"
+	serverPkg "github.com/eclipse-xfsc/microservice-core-go/pkg/server"
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/config"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/common"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/importer"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/importer/layout"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/signer"
+)
+
+const (
+	// reloadDelay collects the events of a change (e.g. the swap of a mounted ConfigMap) into one reload
+	reloadDelay = 500 * time.Millisecond
+	// watchDepth covers the tenant folders and their credentials and images folders
+	watchDepth = 2
+)
+
+// Importer serves the metadata from a local directory, e.g. a mounted ConfigMap volume, and reloads it
+// whenever the directory changes
+type Importer struct {
+	*layout.Reader
+
+	config   config.FileConfig
+	log      logPkg.Logger
+	watcher  *fsnotify.Watcher
+	stop     chan struct{}
+	stopOnce sync.Once
+
+	mu        sync.RWMutex
+	lastError error
+}
+
+var _ importer.Importer = &Importer{}
+
+// NewImporter creates the file importer. If a signer is given, signed_metadata of the directory is replaced
+// by a JWT signed with the key of the tenant.
+func NewImporter(config config.FileConfig, signer *signer.Signer, logger logPkg.Logger) *Importer {
+	return &Importer{
+		Reader: layout.NewReader(config.Path, signer, logger),
+		config: config,
+		log:    logger,
+		stop:   make(chan struct{}),
+	}
+}
+
+func (f *Importer) Start(ctx context.Context, _ *serverPkg.Server, _ *common.Environment) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		f.log.Error(err, "failed to create watcher for file importer")
+		return err
+	}
+	f.watcher = watcher
+
+	f.reload()
+
+	go f.watch(ctx)
+
+	return nil
+}
+
+// Stop ends the watch. It may be called more than once.
+func (f *Importer) Stop() error {
+	var err error
+	f.stopOnce.Do(func() {
+		close(f.stop)
+
+		if f.watcher != nil {
+			err = f.watcher.Close()
+		}
+	})
+
+	return err
+}
+
+func (f *Importer) GotErrors() bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.lastError != nil
+}
+
+func (f *Importer) watch(ctx context.Context) {
+	var reload <-chan time.Time
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-f.stop:
+			return
+		case event, ok := <-f.watcher.Events:
+			if !ok {
+				return
+			}
+
+			f.log.Debug("file event", "name", event.Name, "op", event.Op.String())
+			reload = time.After(reloadDelay)
+		case err, ok := <-f.watcher.Errors:
+			if !ok {
+				return
+			}
+
+			f.log.Error(err, "file watcher failed")
+		case <-reload:
+			reload = nil
+			f.reload()
+		}
+	}
+}
+
+// reload watches new folders and serves a new snapshot of the directory. Invalid files are reported by
+// GotErrors until they are fixed, but the valid parts of the directory are served right away.
+func (f *Importer) reload() {
+	f.addWatches(f.config.Path, watchDepth)
+
+	snapshot, err := f.Load(f.config.Path)
+	if err == nil {
+		f.Activate(snapshot)
+		err = f.Validate(snapshot)
+	}
+
+	if err != nil {
+		f.log.Error(err, "invalid metadata in directory", "path", f.config.Path)
+	} else {
+		f.log.Info("loaded metadata from directory", "path", f.config.Path)
+	}
+
+	f.mu.Lock()
+	f.lastError = err
+	f.mu.Unlock()
+}
+
+// addWatches watches the directory and its subdirectories up to the given depth. Symlinks are followed, as
+// mounted volumes link the visible folders to a hidden, versioned folder.
+func (f *Importer) addWatches(path string, depth int) {
+	if err := f.watcher.Add(path); err != nil {
+		f.log.Error(err, "failed to watch directory", "path", path)
+		return
+	}
+
+	if depth == 0 {
+		return
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		if entry.Name()[0] == '.' {
+			continue
+		}
+
+		child := filepath.Join(path, entry.Name())
+		if info, err := os.Stat(child); err == nil && info.IsDir() {
+			f.addWatches(child, depth-1)
+		}
+	}
+}
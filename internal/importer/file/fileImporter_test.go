@@ -0,0 +1,92 @@
+package file
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/logr"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/config"
+)
+
+func writeIssuer(t *testing.T, dir, credentialEndpoint string) {
+	t.Helper()
+
+	content := `{"credential_issuer": "https://issuer.example/tenant", "credential_endpoint": "` + credentialEndpoint + `"}`
+	if err := os.WriteFile(filepath.Join(dir, "tenant", "issuer.json"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// eventually waits for the condition, which is checked after the reload delay at the earliest
+func eventually(t *testing.T, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(10 * reloadDelay)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met after reload")
+		}
+		time.Sleep(reloadDelay / 10)
+	}
+}
+
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "tenant"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeIssuer(t, dir, "https://issuer.example/tenant/credential")
+
+	logger, err := logr.New("error", false, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := NewImporter(config.FileConfig{Path: dir}, nil, *logger)
+	if err := f.Start(context.Background(), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	defer f.Stop()
+
+	endpoint := func() string {
+		metadata, err := f.GetCredentialIssuerMetadata(context.Background(), "tenant")
+		if err != nil {
+			return ""
+		}
+		return metadata.CredentialEndpoint
+	}
+
+	if f.GotErrors() || endpoint() != "https://issuer.example/tenant/credential" {
+		t.Fatalf("expected the directory to be loaded on start, got errors %v and endpoint %q", f.GotErrors(), endpoint())
+	}
+
+	// a burst of writes is reloaded once, after the files settled
+	writeIssuer(t, dir, "https://issuer.example/tenant/v1/credential")
+	writeIssuer(t, dir, "https://issuer.example/tenant/v2/credential")
+	if got := endpoint(); got != "https://issuer.example/tenant/credential" {
+		t.Fatalf("expected the reload to be delayed, got endpoint %q", got)
+	}
+	eventually(t, func() bool { return endpoint() == "https://issuer.example/tenant/v2/credential" })
+
+	// invalid files are reported until they are fixed
+	writeIssuer(t, dir, "not a url")
+	eventually(t, f.GotErrors)
+
+	writeIssuer(t, dir, "https://issuer.example/tenant/v3/credential")
+	eventually(t, func() bool { return !f.GotErrors() })
+	if got := endpoint(); got != "https://issuer.example/tenant/v3/credential" {
+		t.Fatalf("expected the fixed endpoint, got %q", got)
+	}
+
+	if err := f.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Stop(); err != nil {
+		t.Fatalf("expected a second stop to succeed, got %v", err)
+	}
+}
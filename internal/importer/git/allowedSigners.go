@@ -0,0 +1,271 @@
+package git
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// allowedSigner is a line of git's allowed_signers file (ALLOWED SIGNERS of ssh-keygen(1)):
+//
+//	principals [options] keytype base64-key [comment]
+//
+// Supported options are namespaces, valid-after and valid-before. Lines in authorized_keys format (without
+// principals and options) allow the key for every principal.
+type allowedSigner struct {
+	principals  []string
+	namespaces  []string
+	validAfter  time.Time
+	validBefore time.Time
+	key         ssh.PublicKey
+}
+
+// allows reports whether the signer may sign commits of the principal (the committer email) at the given time
+func (s allowedSigner) allows(principal, namespace string, at time.Time) error {
+	if !matchPatternList(s.principals, principal) {
+		return fmt.Errorf("principal %s is not allowed", principal)
+	}
+
+	if len(s.namespaces) > 0 && !matchPatternList(s.namespaces, namespace) {
+		return fmt.Errorf("namespace %s is not allowed", namespace)
+	}
+
+	if !s.validAfter.IsZero() && at.Before(s.validAfter) {
+		return fmt.Errorf("key is only valid after %s", s.validAfter.Format(time.RFC3339))
+	}
+
+	if !s.validBefore.IsZero() && !at.Before(s.validBefore) {
+		return fmt.Errorf("key is only valid before %s", s.validBefore.Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+// readAllowedSigners reads the signers of a file in git's allowed_signers or authorized_keys format. Lines with
+// unsupported options (e.g. cert-authority) are returned as unsupported and not allowed to sign.
+func readAllowedSigners(path string) (signers []allowedSigner, unsupported []error, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	number := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		number++
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		signer, err := parseAllowedSigner(line)
+		if errors.Is(err, errUnsupportedOption) {
+			unsupported = append(unsupported, fmt.Errorf("line %d: %w", number, err))
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: %w", number, err)
+		}
+
+		signers = append(signers, *signer)
+	}
+
+	return signers, unsupported, scanner.Err()
+}
+
+var errUnsupportedOption = errors.New("unsupported option")
+
+func parseAllowedSigner(line string) (*allowedSigner, error) {
+	fields, err := splitFields(line)
+	if err != nil {
+		return nil, err
+	}
+
+	// authorized_keys format
+	if len(fields) >= 2 {
+		if key, err := parseKey(fields[0], fields[1]); err == nil {
+			return &allowedSigner{principals: []string{"*"}, key: key}, nil
+		}
+	}
+
+	if len(fields) < 3 {
+		return nil, errors.New("missing key")
+	}
+
+	signer := &allowedSigner{principals: splitList(unquote(fields[0]))}
+
+	keyFields := fields[1:]
+	if key, err := parseKey(keyFields[0], keyFields[1]); err == nil {
+		signer.key = key
+		return signer, nil
+	}
+
+	if len(keyFields) < 3 {
+		return nil, errors.New("invalid key")
+	}
+
+	if err := signer.parseOptions(keyFields[0]); err != nil {
+		return nil, err
+	}
+
+	signer.key, err = parseKey(keyFields[1], keyFields[2])
+	if err != nil {
+		return nil, err
+	}
+
+	return signer, nil
+}
+
+func (s *allowedSigner) parseOptions(options string) error {
+	for _, option := range splitList(options) {
+		name, value, _ := strings.Cut(option, "=")
+		value = unquote(value)
+
+		var err error
+		switch strings.ToLower(name) {
+		case "namespaces":
+			s.namespaces = splitList(value)
+		case "valid-after":
+			s.validAfter, err = parseSignerTime(value)
+		case "valid-before":
+			s.validBefore, err = parseSignerTime(value)
+		default:
+			return fmt.Errorf("%w %s", errUnsupportedOption, name)
+		}
+
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func parseKey(keyType, encoded string) (ssh.PublicKey, error) {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := ssh.ParsePublicKey(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if key.Type() != keyType {
+		return nil, fmt.Errorf("key of type %s instead of %s", key.Type(), keyType)
+	}
+
+	return key, nil
+}
+
+// parseSignerTime parses YYYYMMDD[HHMM[SS]] in local time or, with suffix Z, in UTC
+func parseSignerTime(value string) (time.Time, error) {
+	location := time.Local
+	if trimmed, ok := strings.CutSuffix(value, "Z"); ok {
+		value, location = trimmed, time.UTC
+	}
+
+	for _, layout := range []string{"20060102", "200601021504", "20060102150405"} {
+		if len(value) == len(layout) {
+			return time.ParseInLocation(layout, value, location)
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time %q", value)
+}
+
+// splitFields splits the line at whitespace outside of double quotes
+func splitFields(line string) ([]string, error) {
+	var fields []string
+	var field strings.Builder
+
+	quoted := false
+	for _, r := range line {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			field.WriteRune(r)
+		case !quoted && (r == ' ' || r == '\t'):
+			if field.Len() > 0 {
+				fields = append(fields, field.String())
+				field.Reset()
+			}
+		default:
+			field.WriteRune(r)
+		}
+	}
+
+	if quoted {
+		return nil, errors.New("unterminated quote")
+	}
+	if field.Len() > 0 {
+		fields = append(fields, field.String())
+	}
+
+	return fields, nil
+}
+
+// splitList splits a comma separated list, commas within double quotes are kept
+func splitList(list string) []string {
+	var values []string
+
+	quoted, start := false, 0
+	for i, r := range list {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == ',' && !quoted:
+			values = append(values, list[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(values, list[start:])
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+		return value[1 : len(value)-1]
+	}
+
+	return value
+}
+
+// matchPatternList matches the value against a list of patterns with the wildcards * and ?. A matching
+// pattern negated with ! rejects the value.
+func matchPatternList(patterns []string, value string) bool {
+	matched := false
+	for _, pattern := range patterns {
+		negated := strings.HasPrefix(pattern, "!")
+		pattern = strings.TrimPrefix(pattern, "!")
+
+		if !matchPattern(pattern, value) {
+			continue
+		}
+		if negated {
+			return false
+		}
+		matched = true
+	}
+
+	return matched
+}
+
+func matchPattern(pattern, value string) bool {
+	expression := regexp.QuoteMeta(pattern)
+	expression = strings.ReplaceAll(expression, `\*`, ".*")
+	expression = strings.ReplaceAll(expression, `\?`, ".")
+
+	matched, err := regexp.MatchString("^"+expression+"$", value)
+	return err == nil && matched
+}
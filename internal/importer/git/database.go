@@ -0,0 +1,250 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-library/model/credential"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/database"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/importer"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/types"
+)
+
+// syncDatabase stores the active commit in the database, so the NATS gateway serves the same metadata as the
+// REST gateway. Tenants and credential configurations which were removed from the repository are removed from
+// the database as well. A failed sync is repeated with the next run.
+func (g *Importer) syncDatabase(ctx context.Context) {
+	g.mu.RLock()
+	active, synced := g.status.Active, g.status.Synced
+	g.mu.RUnlock()
+
+	if active == "" || active == synced {
+		return
+	}
+
+	err := g.store(ctx)
+	if err != nil {
+		g.log.Error(err, "failed to sync commit into the database", "commit", active)
+	} else {
+		g.log.Info("synced commit into the database", "commit", active)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.status.SyncError = ""
+	if err != nil {
+		g.status.SyncError = err.Error()
+		return
+	}
+
+	g.status.Synced = active
+}
+
+func (g *Importer) store(ctx context.Context) error {
+	tenants := g.Tenants()
+
+	var errs []error
+	for _, tenantID := range tenants {
+		if err := g.storeTenant(ctx, tenantID); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", tenantID, err))
+		}
+	}
+
+	stored, err := g.svc.ListTenants(ctx)
+	if err != nil {
+		return errors.Join(append(errs, err)...)
+	}
+
+	// tenants with only verifier or authorization server metadata are not listed by the database
+	removed := append(stored, g.syncedTenants...)
+	slices.Sort(removed)
+
+	for _, tenantID := range slices.Compact(removed) {
+		if slices.Contains(tenants, tenantID) {
+			continue
+		}
+
+		// tenants of other sources, e.g. registered via NATS, are not part of the repository
+		owned := slices.Contains(g.syncedTenants, tenantID)
+		if !owned {
+			owned, err = g.svc.OwnsIssuer(ctx, tenantID)
+			if err != nil && !errors.Is(err, database.ErrNotFound) {
+				errs = append(errs, fmt.Errorf("%s: %w", tenantID, err))
+				continue
+			}
+		}
+		if !owned {
+			continue
+		}
+
+		if err := g.removeTenant(ctx, tenantID); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", tenantID, err))
+			continue
+		}
+
+		g.log.Info("removed tenant which is no longer part of the repository", "tenant", tenantID)
+	}
+
+	if len(errs) == 0 {
+		g.syncedTenants = tenants
+	}
+
+	return errors.Join(errs...)
+}
+
+func (g *Importer) storeTenant(ctx context.Context, tenantID string) error {
+	if g.config.PublicURL == "" && g.UsesTemplates(tenantID) {
+		return errors.New("templates can't be stored without public URL")
+	}
+
+	ctx = g.syncContext(ctx, tenantID)
+
+	issuer, err := g.GetCredentialIssuerMetadata(ctx, tenantID)
+	switch {
+	case errors.Is(err, importer.ErrNotFound):
+		if err := g.removeIssuer(ctx, tenantID); err != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	default:
+		if err := g.storeIssuer(ctx, tenantID, *issuer); err != nil {
+			return err
+		}
+
+		// removed together with the issuer
+		if err := storeDocument(ctx, tenantID, g.GetOpenIDConfiguration, g.svc.UpsertOpenIDConfiguration, nil); err != nil {
+			return err
+		}
+
+		if err := storeDocument(ctx, tenantID, g.GetJwtVcIssuerMetadata, g.svc.UpsertJwtVcIssuer, nil); err != nil {
+			return err
+		}
+	}
+
+	if err := storeDocument(ctx, tenantID, g.GetAuthorizationServerMetadata, g.asSvc.UpsertAuthorizationServer, g.asSvc.DeleteAuthorizationServer); err != nil {
+		return err
+	}
+
+	return storeDocument(ctx, tenantID, g.GetVerifierMetadata, g.vSvc.UpsertVerifier, g.vSvc.DeleteVerifier)
+}
+
+// storeIssuer replaces the issuer and removes the credential configurations which are not part of it. Issuers
+// of other sources are not overwritten.
+func (g *Importer) storeIssuer(ctx context.Context, tenantID string, issuer credential.IssuerMetadata) error {
+	since := time.Now()
+
+	stored, err := g.svc.GetIssuer(ctx, tenantID, false)
+	if err != nil && !errors.Is(err, database.ErrNotFound) {
+		return err
+	}
+
+	if stored != nil {
+		owned, err := g.svc.OwnsIssuer(ctx, tenantID)
+		if err != nil {
+			return err
+		}
+		if !owned {
+			return errors.New("issuer is managed by another source")
+		}
+	}
+
+	// the credential issuer identifies the record and can't be updated
+	if stored != nil && stored.CredentialIssuer != issuer.CredentialIssuer {
+		if err := g.svc.DeleteIssuer(ctx, tenantID); err != nil {
+			return err
+		}
+	}
+
+	// writes the complete record, fields removed from issuer.json are cleared
+	if err := g.svc.UpsertIssuer(ctx, tenantID, issuer); err != nil {
+		return err
+	}
+
+	_, err = g.svc.Reconcile(ctx, tenantID, since, true)
+	return err
+}
+
+// removeIssuer deletes the issuer of the tenant, if it was stored by the git importer, and the credential
+// configurations stored by the git importer
+func (g *Importer) removeIssuer(ctx context.Context, tenantID string) error {
+	owned, err := g.svc.OwnsIssuer(ctx, tenantID)
+	if err != nil && !errors.Is(err, database.ErrNotFound) {
+		return err
+	}
+
+	if owned {
+		return g.svc.DeleteIssuer(ctx, tenantID)
+	}
+
+	_, err = g.svc.Reconcile(ctx, tenantID, time.Now(), true)
+	return err
+}
+
+func (g *Importer) removeTenant(ctx context.Context, tenantID string) error {
+	if err := g.removeIssuer(ctx, tenantID); err != nil {
+		return err
+	}
+
+	if err := g.asSvc.DeleteAuthorizationServer(ctx, tenantID); err != nil && !errors.Is(err, database.ErrNotFound) {
+		return err
+	}
+
+	if err := g.vSvc.DeleteVerifier(ctx, tenantID); err != nil && !errors.Is(err, database.ErrNotFound) {
+		return err
+	}
+
+	return nil
+}
+
+// storeDocument upserts the document of the tenant or removes it, if it is not part of the repository
+func storeDocument[T any](
+	ctx context.Context,
+	tenantID string,
+	get func(context.Context, string) (*T, error),
+	upsert func(context.Context, string, T) error,
+	remove func(context.Context, string) error,
+) error {
+	document, err := get(ctx, tenantID)
+	if errors.Is(err, importer.ErrNotFound) {
+		if remove == nil {
+			return nil
+		}
+
+		if err := remove(ctx, tenantID); err != nil && !errors.Is(err, database.ErrNotFound) {
+			return err
+		}
+
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return upsert(ctx, tenantID, *document)
+}
+
+// syncContext carries the template data of the public URL, as there is no request to take it from. Tenants with
+// templates are not stored without public URL.
+func (g *Importer) syncContext(ctx context.Context, tenantID string) context.Context {
+	if g.config.PublicURL == "" {
+		return ctx
+	}
+
+	// validated by the config
+	public, _ := url.Parse(g.config.PublicURL)
+	baseURL, _ := url.JoinPath(g.config.PublicURL, "v1", "tenants", tenantID)
+
+	return types.WithTemplateData(ctx, types.TemplateData{
+		Origin:   public.Scheme + "://" + public.Host,
+		TenantId: tenantID,
+		BaseURL:  baseURL,
+	})
+}
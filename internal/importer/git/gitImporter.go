@@ -2,12 +2,13 @@ package git
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
-	"strings"
-
-	"github.com/eclipse-xfsc/oid4-vci-vp-library/model/credential"
+	"path/filepath"
+	"sync"
+	"time"
 
 	ctxPkg "github.com/eclipse-xfsc/microservice-core-go/pkg/ctx"
 	logPkg "github.com/eclipse-xfsc/microservice-core-go/pkg/logr"
@@ -15,44 +16,126 @@ import (
 
 	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/config"
 	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/common"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/importer"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/importer/layout"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/service"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/signer"
 	"github.com/gin-gonic/gin"
 	"github.com/madflojo/tasks"
+	"gopkg.in/src-d/go-billy.v4/osfs"
 	"gopkg.in/src-d/go-git.v4"
+	gitConfig "gopkg.in/src-d/go-git.v4/config"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	gitHTTP "gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+	gitSSH "gopkg.in/src-d/go-git.v4/plumbing/transport/ssh"
 )
 
+// Importer serves the metadata from a checkout of a git repository. Every fetched commit is checked out into
+// its own folder, loaded into a snapshot and validated before it replaces the active one, so a broken commit
+// never goes live.
 type Importer struct {
+	*layout.Reader
+
 	config        config.GitConfig
 	log           logPkg.Logger
 	taskScheduler *tasks.Scheduler
 	folder        string
 	repo          *git.Repository
-	lastError     error
+	defaultBranch string
+	svc           service.IssuerService
+	asSvc         service.AuthorizationServerService
+	vSvc          service.VerifierService
+	syncedTenants []string
+	triggers      chan struct{}
+	cancel        context.CancelFunc
+	syncMu        sync.Mutex
+
+	mu     sync.RWMutex
+	status Status
+}
+
+// Status of the repository as exposed in the health output
+type Status struct {
+	Repo        string     `json:"repo"`
+	Active      string     `json:"active,omitempty"`
+	ActivatedAt *time.Time `json:"activated_at,omitempty"`
+	SignedBy    string     `json:"signed_by,omitempty"`
+	Failed      string     `json:"failed,omitempty"`
+	FailedError string     `json:"failed_error,omitempty"`
+	LastRun     *time.Time `json:"last_run,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+	LastWebhook *time.Time `json:"last_webhook,omitempty"`
+	Synced      string     `json:"synced,omitempty"`
+	SyncError   string     `json:"sync_error,omitempty"`
 }
 
+var _ importer.Importer = &Importer{}
+
 const (
-	issuerJSON              = "issuer.json"
-	credentialsSupportedDir = "credentials"
-	cacheDir                = "cache"
+	cacheDir = "cache"
+	// repoDir contains the bare clone, the checkouts are named by their commit hash
+	repoDir = "repo"
+	// activeLink links to the checkout of the active commit
+	activeLink = "active"
+)
+
+var (
+	remoteBranchesRefSpec = gitConfig.RefSpec("+refs/heads/*:refs/remotes/" + git.DefaultRemoteName + "/*")
+	tagsRefSpec           = gitConfig.RefSpec("+refs/tags/*:refs/tags/*")
 )
 
-func NewImporter(config config.GitConfig, logger logPkg.Logger) *Importer {
+// NewImporter creates the git importer. If a signer is given, signed_metadata of the repository is replaced
+// by a JWT signed with the key of the tenant. The services store the active commit, if the database sync
+// is enabled.
+func NewImporter(
+	config config.GitConfig,
+	svc service.IssuerService,
+	asSvc service.AuthorizationServerService,
+	vSvc service.VerifierService,
+	signer *signer.Signer,
+	logger logPkg.Logger,
+) *Importer {
+	folder := filepath.Join(os.TempDir(), cacheDir)
+
 	return &Importer{
+		Reader:        layout.NewReader(filepath.Join(folder, activeLink), signer, logger),
 		config:        config,
-		folder:        assemblePath(os.TempDir(), cacheDir),
+		folder:        folder,
 		log:           logger,
 		taskScheduler: tasks.New(),
+		svc:           svc,
+		asSvc:         asSvc,
+		vSvc:          vSvc,
+		triggers:      make(chan struct{}, 1),
+		status:        Status{Repo: redact(config.Repo)},
 	}
 }
 
-func (g *Importer) Start(ctx context.Context, server *serverPkg.Server, _ *common.Environment) error {
+func (g *Importer) Start(ctx context.Context, server *serverPkg.Server, env *common.Environment) error {
+	ctx = ctxPkg.WithLogger(ctx, g.log)
+	ctx, g.cancel = context.WithCancel(ctx)
+
 	server.Add(func(rg *gin.RouterGroup) {
-		rg.Static(g.config.ImagePath, g.folder)
+		if g.config.WebhookSecret != "" {
+			webhookGroup(rg).POST(g.config.WebhookPath, g.WebhookHandler)
+		}
+	})
+
+	env.AddHealthDetail("git", func() any {
+		return g.Status()
 	})
 
+	// the scheduler runs the first time after the interval
+	_ = g.sync(ctx)
+
 	_, err := g.taskScheduler.Add(&tasks.Task{
-		TaskContext: tasks.TaskContext{Context: ctx},
-		Interval:    g.config.Interval,
-		TaskFunc:    g.checkout,
+		TaskContext:       tasks.TaskContext{Context: ctx},
+		Interval:          g.config.Interval,
+		RunSingleInstance: true,
+		FuncWithTaskContext: func(tc tasks.TaskContext) error {
+			return g.sync(tc.Context)
+		},
 	})
 
 	if err != nil {
@@ -60,124 +143,322 @@ func (g *Importer) Start(ctx context.Context, server *serverPkg.Server, _ *commo
 		return err
 	}
 
+	go g.debounce(ctx)
+
 	return nil
 }
 
 func (g *Importer) Stop() error {
 	g.taskScheduler.Stop()
+
+	if g.cancel != nil {
+		g.cancel()
+	}
+
 	return nil
 }
 
+// GotErrors reports whether no commit is active. Failing fetches and rejected commits keep the active commit
+// and are only part of the status.
 func (g *Importer) GotErrors() bool {
-	return g.lastError != nil
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	return g.status.Active == ""
 }
 
-func (g *Importer) GetCredentialIssuerMetadata(ctx context.Context, tenantID string) (*credential.IssuerMetadata, error) {
-	issuerPath := assemblePath(g.folder, tenantID)
+func (g *Importer) Status() Status {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
 
-	issuerData, err := os.ReadFile(assemblePath(issuerPath, issuerJSON))
-	if err != nil {
-		g.log.Error(err, "failed to read file from disk")
-		return nil, err
+	return g.status
+}
+
+// sync fetches the repository and activates the revision. It is called by the scheduler and the webhook,
+// one at a time.
+func (g *Importer) sync(ctx context.Context) error {
+	g.syncMu.Lock()
+	defer g.syncMu.Unlock()
+
+	now := time.Now()
+
+	err := g.fetch()
+
+	var hash plumbing.Hash
+	if err == nil {
+		hash, err = g.revision()
 	}
 
-	var issuer credential.IssuerMetadata
-	if err := json.Unmarshal(issuerData, &issuer); err != nil {
-		return nil, fmt.Errorf("failed to decode issuer.json: %w", err)
+	if err == nil {
+		err = g.activate(hash)
 	}
 
-	issuer.CredentialConfigurationsSupported, err = g.collectCredentialsSupported(ctx, assemblePath(issuerPath, credentialsSupportedDir))
 	if err != nil {
-		return nil, fmt.Errorf("failed to collectCredentialsSupported: %w", err)
+		g.log.Error(err, "failed to check out repository", "repo", g.status.Repo)
 	}
 
-	return &issuer, nil
-}
-
-func (g *Importer) collectCredentialsSupported(ctx context.Context, path string) (map[string]credential.CredentialConfiguration, error) {
-	logger := ctxPkg.GetLogger(ctx)
-
-	files, err := os.ReadDir(path)
+	g.mu.Lock()
+	g.status.LastRun = &now
+	g.status.LastError = ""
 	if err != nil {
-		logger.Error(err, "Error reading Directory")
-		return nil, err
+		g.status.LastError = err.Error()
 	}
+	g.mu.Unlock()
 
-	credentials := make(map[string]credential.CredentialConfiguration)
-	for _, file := range files {
-		if file.IsDir() {
-			continue
-		}
+	if g.config.SyncDatabase {
+		g.syncDatabase(ctx)
+	}
 
-		data, err := os.ReadFile(assemblePath(path, file.Name()))
-		if err != nil {
-			continue
-		}
+	return err
+}
 
-		var credential credential.CredentialConfiguration
-		if err := json.Unmarshal(data, &credential); err != nil {
-			g.log.Error(err, "failed to unmarshal credentials supported")
-			continue
-		}
+// fetch clones the repository on the first run and fetches it afterwards
+func (g *Importer) fetch() error {
+	auth, err := g.auth()
+	if err != nil {
+		return err
+	}
 
-		credentials[file.Name()] = credential
+	if g.repo == nil {
+		return g.clone(auth)
 	}
 
-	return credentials, nil
+	err = g.repo.Fetch(&git.FetchOptions{
+		RemoteName: git.DefaultRemoteName,
+		RefSpecs:   []gitConfig.RefSpec{remoteBranchesRefSpec, tagsRefSpec},
+		Auth:       auth,
+		Tags:       git.AllTags,
+		Force:      true,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return err
+	}
+
+	return nil
 }
 
-func (g *Importer) checkout() error {
+func (g *Importer) clone(auth transport.AuthMethod) error {
 	g.log.Info("git clone " + g.folder)
 
-	cloneOpt := git.CloneOptions{
+	// checkouts of a previous run may belong to another repository or revision
+	if err := os.RemoveAll(g.folder); err != nil {
+		return err
+	}
+
+	repo, err := git.PlainClone(filepath.Join(g.folder, repoDir), true, &git.CloneOptions{
 		URL:      g.config.Repo,
+		Auth:     auth,
+		Tags:     git.AllTags,
 		Progress: os.Stdout,
+	})
+	if err != nil {
+		return err
 	}
 
-	if token := g.config.Token; token != "" {
-		cloneOpt.URL = strings.ReplaceAll(cloneOpt.URL, "https://", "https://token:"+token+"@")
+	head, err := repo.Head()
+	if err != nil {
+		return err
 	}
 
-	if g.repo == nil {
-		gitRepo, err := git.PlainClone(g.folder, false, &cloneOpt)
-		if err != nil {
-			return err
-		}
+	g.repo = repo
 
-		g.repo = gitRepo
+	// read by the webhook
+	g.mu.Lock()
+	g.defaultBranch = head.Name().Short()
+	g.mu.Unlock()
 
+	return nil
+}
+
+// activate checks out the commit into its own folder and validates all tenants. Valid commits replace the
+// active checkout, invalid ones are discarded and the active commit stays in place. With VerifySignatures,
+// commits without signature of an allowed key are rejected before checkout.
+func (g *Importer) activate(hash plumbing.Hash) error {
+	commit := hash.String()
+
+	g.mu.RLock()
+	active, failed := g.status.Active, g.status.Failed
+	g.mu.RUnlock()
+
+	if commit == active || commit == failed {
 		return nil
 	}
 
-	w, err := g.repo.Worktree()
+	var signedBy string
+	if g.config.VerifySignatures {
+		var err error
+		if signedBy, err = g.verifySignature(hash); err != nil {
+			// a push with a leaked token must not redirect the wallets, the error log is the alert
+			g.log.Error(err, "rejected commit without signature of an allowed key", "commit", commit, "active", active)
+			g.reject(commit, err)
+
+			return nil
+		}
+	}
+
+	dir := filepath.Join(g.folder, commit)
+	if err := g.checkout(hash, dir); err != nil {
+		return err
+	}
+
+	snapshot, err := g.Load(dir)
+	if err == nil {
+		err = g.Validate(snapshot)
+	}
+
 	if err != nil {
+		g.log.Error(err, "rejected invalid commit", "commit", commit, "active", active)
+		g.reject(commit, err)
+
+		return os.RemoveAll(dir)
+	}
+
+	// the images are served from the link, which is replaced atomically like the snapshot
+	link := filepath.Join(g.folder, activeLink)
+	if err := os.Symlink(commit, link+".tmp"); err != nil {
+		return err
+	}
+
+	if err := os.Rename(link+".tmp", link); err != nil {
 		return err
 	}
 
-	if err := w.Pull(&git.PullOptions{RemoteName: "origin"}); err != nil {
+	g.Activate(snapshot)
+
+	if active != "" {
+		if err := os.RemoveAll(filepath.Join(g.folder, active)); err != nil {
+			g.log.Error(err, "failed to remove previous checkout", "commit", active)
+		}
+	}
+
+	now := time.Now()
+
+	g.mu.Lock()
+	g.status.Active = commit
+	g.status.ActivatedAt = &now
+	g.status.SignedBy = signedBy
+	g.status.Failed = ""
+	g.status.FailedError = ""
+	g.mu.Unlock()
+
+	g.log.Info("Activated commit "+commit, "previous", active)
+
+	return nil
+}
+
+// reject records the commit as failed, so it is not checked again until the revision moves on
+func (g *Importer) reject(commit string, err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.status.Failed = commit
+	g.status.FailedError = err.Error()
+}
+
+// checkout writes the files of the commit into the folder. The checkouts share the objects of the bare clone.
+func (g *Importer) checkout(hash plumbing.Hash, dir string) error {
+	if err := os.RemoveAll(dir); err != nil {
 		return err
 	}
 
-	ref, err := g.repo.Head()
+	repo, err := git.Open(g.repo.Storer, osfs.New(dir))
 	if err != nil {
 		return err
 	}
 
-	commit, err := g.repo.CommitObject(ref.Hash())
+	w, err := repo.Worktree()
 	if err != nil {
 		return err
 	}
 
-	g.log.Info("Pulled " + commit.String())
+	return w.Checkout(&git.CheckoutOptions{Hash: hash, Force: true})
+}
 
-	return nil
+// revision resolves the configured commit, tag or branch. Without pin, the default branch of the remote is used.
+func (g *Importer) revision() (plumbing.Hash, error) {
+	switch {
+	case g.config.Commit != "":
+		hash := plumbing.NewHash(g.config.Commit)
+		if _, err := g.repo.CommitObject(hash); err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to resolve commit %s: %w", g.config.Commit, err)
+		}
+
+		return hash, nil
+	case g.config.Tag != "":
+		ref, err := g.repo.Tag(g.config.Tag)
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to resolve tag %s: %w", g.config.Tag, err)
+		}
+
+		// annotated tags point to a tag object instead of the commit
+		if tag, err := g.repo.TagObject(ref.Hash()); err == nil {
+			commit, err := tag.Commit()
+			if err != nil {
+				return plumbing.ZeroHash, fmt.Errorf("failed to resolve tag %s: %w", g.config.Tag, err)
+			}
+
+			return commit.Hash, nil
+		}
+
+		return ref.Hash(), nil
+	default:
+		branch := g.config.Branch
+		if branch == "" {
+			branch = g.defaultBranch
+		}
+
+		ref, err := g.repo.Reference(plumbing.NewRemoteReferenceName(git.DefaultRemoteName, branch), true)
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to resolve branch %s: %w", branch, err)
+		}
+
+		return ref.Hash(), nil
+	}
+}
+
+// auth returns the authentication for the repository, which is nil for public and local repositories
+func (g *Importer) auth() (transport.AuthMethod, error) {
+	if g.config.SSHKeyPath != "" {
+		keys, err := gitSSH.NewPublicKeysFromFile(g.config.SSHUser, g.config.SSHKeyPath, g.config.SSHKeyPassphrase)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load ssh key: %w", err)
+		}
+
+		var knownHosts []string
+		if g.config.KnownHostsPath != "" {
+			knownHosts = append(knownHosts, g.config.KnownHostsPath)
+		}
+
+		keys.HostKeyCallback, err = gitSSH.NewKnownHostsCallback(knownHosts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load known hosts: %w", err)
+		}
+
+		return keys, nil
+	}
+
+	if g.config.Password != "" {
+		return &gitHTTP.BasicAuth{Username: g.config.Username, Password: g.config.Password}, nil
+	}
+
+	if g.config.Token != "" {
+		username := g.config.Username
+		if username == "" {
+			username = "token"
+		}
+
+		return &gitHTTP.BasicAuth{Username: username, Password: g.config.Token}, nil
+	}
+
+	return nil, nil
 }
 
-func assemblePath(paths ...string) string {
-	path := ""
-	for _, file := range paths {
-		path = fmt.Sprintf("%c%s", os.PathSeparator, file)
+// redact removes the password of the repository URL
+func redact(repo string) string {
+	u, err := url.Parse(repo)
+	if err != nil {
+		return repo
 	}
 
-	return strings.TrimLeft(path, string(os.PathSeparator))
+	return u.Redacted()
 }
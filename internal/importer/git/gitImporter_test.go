@@ -0,0 +1,174 @@
+package git
+
+import (
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/logr"
+	"gopkg.in/src-d/go-billy.v4/memfs"
+	"gopkg.in/src-d/go-billy.v4/osfs"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/cache"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/storage/filesystem"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/config"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/service"
+)
+
+// remote is a bare repository, whose commits are written through an in-memory worktree
+type remote struct {
+	t    *testing.T
+	dir  string
+	repo *git.Repository
+}
+
+func newRemote(t *testing.T) *remote {
+	t.Helper()
+
+	dir := filepath.Join(t.TempDir(), "remote.git")
+
+	repo, err := git.Init(filesystem.NewStorage(osfs.New(dir), cache.NewObjectLRUDefault()), memfs.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &remote{t: t, dir: dir, repo: repo}
+}
+
+func (r *remote) signature() *object.Signature {
+	return &object.Signature{Name: "test", Email: "test@example.org", When: time.Now()}
+}
+
+// commit writes the content into the README of the checked out branch
+func (r *remote) commit(content string) plumbing.Hash {
+	r.t.Helper()
+
+	w, err := r.repo.Worktree()
+	if err != nil {
+		r.t.Fatal(err)
+	}
+
+	file, err := w.Filesystem.Create("README.md")
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	if _, err := io.WriteString(file, content); err != nil {
+		r.t.Fatal(err)
+	}
+	if err := file.Close(); err != nil {
+		r.t.Fatal(err)
+	}
+
+	if _, err := w.Add("README.md"); err != nil {
+		r.t.Fatal(err)
+	}
+
+	hash, err := w.Commit(content, &git.CommitOptions{Author: r.signature()})
+	if err != nil {
+		r.t.Fatal(err)
+	}
+
+	return hash
+}
+
+// checkout switches to the branch, which is created at the hash if it is given. The checked out branch is the
+// default branch of clones.
+func (r *remote) checkout(name string, hash plumbing.Hash) {
+	r.t.Helper()
+
+	w, err := r.repo.Worktree()
+	if err != nil {
+		r.t.Fatal(err)
+	}
+
+	err = w.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName(name), Hash: hash, Create: !hash.IsZero()})
+	if err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+func (r *remote) tag(name string, hash plumbing.Hash, annotated bool) {
+	r.t.Helper()
+
+	var opts *git.CreateTagOptions
+	if annotated {
+		opts = &git.CreateTagOptions{Tagger: r.signature(), Message: name}
+	}
+
+	if _, err := r.repo.CreateTag(name, hash, opts); err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+func TestRevision(t *testing.T) {
+	remote := newRemote(t)
+
+	released := remote.commit("released")
+	head := remote.commit("head")
+	remote.tag("v1.0.0", released, true)
+	remote.tag("latest", head, false)
+
+	remote.checkout("hotfix", released)
+	hotfix := remote.commit("hotfix")
+	remote.checkout("master", plumbing.ZeroHash)
+
+	tests := []struct {
+		name    string
+		config  config.GitConfig
+		want    plumbing.Hash
+		wantErr bool
+	}{
+		{name: "default branch", want: head},
+		{name: "branch", config: config.GitConfig{Branch: "hotfix"}, want: hotfix},
+		{name: "annotated tag", config: config.GitConfig{Tag: "v1.0.0"}, want: released},
+		{name: "lightweight tag", config: config.GitConfig{Tag: "latest"}, want: head},
+		{name: "commit", config: config.GitConfig{Commit: released.String()}, want: released},
+		{name: "commit takes precedence", config: config.GitConfig{Branch: "hotfix", Commit: released.String()}, want: released},
+		{name: "unknown branch", config: config.GitConfig{Branch: "missing"}, wantErr: true},
+		{name: "unknown tag", config: config.GitConfig{Tag: "v2.0.0"}, wantErr: true},
+		{name: "unknown commit", config: config.GitConfig{Commit: plumbing.ZeroHash.String()}, wantErr: true},
+	}
+
+	logger, err := logr.New("error", false, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.Repo = "file://" + remote.dir
+
+			g := NewImporter(tt.config, service.IssuerService{}, service.AuthorizationServerService{},
+				service.VerifierService{}, nil, *logger)
+			g.folder = t.TempDir()
+
+			if err := g.fetch(); err != nil {
+				t.Fatal(err)
+			}
+
+			// fetches after the clone keep the pins resolvable
+			if err := g.fetch(); err != nil {
+				t.Fatal(err)
+			}
+
+			hash, err := g.revision()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %s", hash)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if hash != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, hash)
+			}
+		})
+	}
+}
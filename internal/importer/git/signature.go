@@ -0,0 +1,188 @@
+package git
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+const (
+	sshSignatureBegin = "-----BEGIN SSH SIGNATURE-----"
+	sshSignatureEnd   = "-----END SSH SIGNATURE-----"
+	// sshSignatureMagic starts the blob of SSH signatures (PROTOCOL.sshsig of OpenSSH)
+	sshSignatureMagic = "SSHSIG"
+	// sshSignatureNamespace is used by git for commit signatures
+	sshSignatureNamespace = "git"
+)
+
+// verifySignature checks that the commit is signed by one of the allowed OpenPGP or SSH keys and returns the
+// fingerprint of the key. The keys are read for every commit, so they can be rotated without restart.
+func (g *Importer) verifySignature(hash plumbing.Hash) (string, error) {
+	commit, err := g.repo.CommitObject(hash)
+	if err != nil {
+		return "", err
+	}
+
+	if commit.PGPSignature == "" {
+		return "", errors.New("commit is not signed")
+	}
+
+	if strings.HasPrefix(commit.PGPSignature, sshSignatureBegin) {
+		return g.verifySSHSignature(commit)
+	}
+
+	return g.verifyPGPSignature(commit)
+}
+
+func (g *Importer) verifyPGPSignature(commit *object.Commit) (string, error) {
+	if g.config.PGPKeyRingPath == "" {
+		return "", errors.New("commit is signed with OpenPGP, but no OpenPGP keys are allowed")
+	}
+
+	keyRing, err := os.ReadFile(g.config.PGPKeyRingPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read OpenPGP keys: %w", err)
+	}
+
+	entity, err := commit.Verify(string(keyRing))
+	if err != nil {
+		return "", fmt.Errorf("commit is not signed by an allowed OpenPGP key: %w", err)
+	}
+
+	return fmt.Sprintf("%X", entity.PrimaryKey.Fingerprint), nil
+}
+
+func (g *Importer) verifySSHSignature(commit *object.Commit) (string, error) {
+	if g.config.SSHAllowedSignersPath == "" {
+		return "", errors.New("commit is signed with SSH, but no SSH keys are allowed")
+	}
+
+	allowed, unsupported, err := readAllowedSigners(g.config.SSHAllowedSignersPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read allowed SSH signers: %w", err)
+	}
+
+	for _, err := range unsupported {
+		g.log.Info("ignored allowed SSH signer", "err", err.Error())
+	}
+
+	encoded := &plumbing.MemoryObject{}
+	if err := commit.EncodeWithoutSignature(encoded); err != nil {
+		return "", err
+	}
+
+	reader, err := encoded.Reader()
+	if err != nil {
+		return "", err
+	}
+
+	message, err := io.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+
+	key, err := verifySSHSignature(commit.PGPSignature, message)
+	if err != nil {
+		return "", err
+	}
+
+	fingerprint := ssh.FingerprintSHA256(key)
+
+	// git verifies the signature for the principals of the key, the committer has to be one of them
+	var errs []error
+	for _, signer := range allowed {
+		if !bytes.Equal(signer.key.Marshal(), key.Marshal()) {
+			continue
+		}
+
+		err := signer.allows(commit.Committer.Email, sshSignatureNamespace, commit.Committer.When)
+		if err == nil {
+			return fingerprint, nil
+		}
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return "", fmt.Errorf("commit is signed by SSH key %s, which is not allowed: %w", fingerprint, errors.Join(errs...))
+	}
+
+	return "", fmt.Errorf("commit is signed by SSH key %s, which is not allowed", fingerprint)
+}
+
+// verifySSHSignature verifies the armored SSH signature of the message and returns the key it was created
+// with. The signed data is described in PROTOCOL.sshsig of OpenSSH.
+func verifySSHSignature(armored string, message []byte) (ssh.PublicKey, error) {
+	body := strings.TrimSpace(armored)
+	body = strings.TrimPrefix(body, sshSignatureBegin)
+	body = strings.TrimSuffix(body, sshSignatureEnd)
+
+	data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(body), ""))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode SSH signature: %w", err)
+	}
+
+	if !bytes.HasPrefix(data, []byte(sshSignatureMagic)) {
+		return nil, errors.New("invalid SSH signature")
+	}
+
+	var blob struct {
+		Version       uint32
+		PublicKey     []byte
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Signature     []byte
+	}
+	if err := ssh.Unmarshal(data[len(sshSignatureMagic):], &blob); err != nil {
+		return nil, fmt.Errorf("failed to decode SSH signature: %w", err)
+	}
+
+	if blob.Namespace != sshSignatureNamespace {
+		return nil, fmt.Errorf("SSH signature of namespace %q instead of %q", blob.Namespace, sshSignatureNamespace)
+	}
+
+	var h hash.Hash
+	switch blob.HashAlgorithm {
+	case "sha256":
+		h = sha256.New()
+	case "sha512":
+		h = sha512.New()
+	default:
+		return nil, fmt.Errorf("unsupported hash algorithm %q of SSH signature", blob.HashAlgorithm)
+	}
+	h.Write(message)
+
+	key, err := ssh.ParsePublicKey(blob.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode key of SSH signature: %w", err)
+	}
+
+	var signature ssh.Signature
+	if err := ssh.Unmarshal(blob.Signature, &signature); err != nil {
+		return nil, fmt.Errorf("failed to decode SSH signature: %w", err)
+	}
+
+	signed := append([]byte(sshSignatureMagic), ssh.Marshal(struct {
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Hash          []byte
+	}{blob.Namespace, blob.Reserved, blob.HashAlgorithm, h.Sum(nil)})...)
+
+	if err := key.Verify(signed, &signature); err != nil {
+		return nil, fmt.Errorf("invalid SSH signature: %w", err)
+	}
+
+	return key, nil
+}
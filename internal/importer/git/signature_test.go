@@ -0,0 +1,168 @@
+package git
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// signSSH creates an armored SSH signature like ssh-keygen -Y sign
+func signSSH(t *testing.T, signer ssh.Signer, namespace, hashAlgorithm string, message []byte) string {
+	t.Helper()
+
+	var digest []byte
+	switch hashAlgorithm {
+	case "sha256":
+		sum := sha256.Sum256(message)
+		digest = sum[:]
+	default:
+		sum := sha512.Sum512(message)
+		digest = sum[:]
+	}
+
+	signed := append([]byte(sshSignatureMagic), ssh.Marshal(struct {
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Hash          []byte
+	}{namespace, "", hashAlgorithm, digest})...)
+
+	signature, err := signer.Sign(rand.Reader, signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blob := append([]byte(sshSignatureMagic), ssh.Marshal(struct {
+		Version       uint32
+		PublicKey     []byte
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Signature     []byte
+	}{1, signer.PublicKey().Marshal(), namespace, "", hashAlgorithm, ssh.Marshal(signature)})...)
+
+	encoded := base64.StdEncoding.EncodeToString(blob)
+
+	var armored strings.Builder
+	armored.WriteString(sshSignatureBegin + "\n")
+	for len(encoded) > 70 {
+		armored.WriteString(encoded[:70] + "\n")
+		encoded = encoded[70:]
+	}
+	armored.WriteString(encoded + "\n" + sshSignatureEnd + "\n")
+
+	return armored.String()
+}
+
+func newSSHSigner(t *testing.T) ssh.Signer {
+	t.Helper()
+
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signer, err := ssh.NewSignerFromKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signer
+}
+
+func TestVerifySSHSignature(t *testing.T) {
+	signer := newSSHSigner(t)
+	message := []byte("tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\n\ncommit\n")
+
+	tests := []struct {
+		name    string
+		armored string
+		message []byte
+		wantErr bool
+	}{
+		{name: "sha512", armored: signSSH(t, signer, "git", "sha512", message), message: message},
+		{name: "sha256", armored: signSSH(t, signer, "git", "sha256", message), message: message},
+		{name: "other message", armored: signSSH(t, signer, "git", "sha512", message), message: []byte("other"), wantErr: true},
+		{name: "other namespace", armored: signSSH(t, signer, "file", "sha512", message), message: message, wantErr: true},
+		{name: "unsupported hash", armored: signSSH(t, signer, "git", "md5", message), message: message, wantErr: true},
+		{name: "not base64", armored: sshSignatureBegin + "\n!!!\n" + sshSignatureEnd, message: message, wantErr: true},
+		{name: "no ssh signature", armored: sshSignatureBegin + "\nAAAA\n" + sshSignatureEnd, message: message, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := verifySSHSignature(tt.armored, tt.message)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+
+			if !tt.wantErr && ssh.FingerprintSHA256(key) != ssh.FingerprintSHA256(signer.PublicKey()) {
+				t.Fatalf("expected key %s, got %s", ssh.FingerprintSHA256(signer.PublicKey()), ssh.FingerprintSHA256(key))
+			}
+		})
+	}
+}
+
+func TestAllowedSigner(t *testing.T) {
+	key := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(newSSHSigner(t).PublicKey())))
+	signedAt := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		line            string
+		principal       string
+		wantParseErr    bool
+		wantUnsupported bool
+		wantAllowed     bool
+	}{
+		{name: "authorized_keys", line: key + " comment", principal: "dev@example.org", wantAllowed: true},
+		{name: "principal", line: "dev@example.org " + key, principal: "dev@example.org", wantAllowed: true},
+		{name: "other principal", line: "dev@example.org " + key, principal: "ops@example.org"},
+		{name: "principal list", line: "ops@example.org,dev@example.org " + key, principal: "dev@example.org", wantAllowed: true},
+		{name: "quoted principal list", line: `"ops@example.org,dev@example.org" ` + key, principal: "dev@example.org", wantAllowed: true},
+		{name: "wildcard", line: "*@example.org " + key, principal: "dev@example.org", wantAllowed: true},
+		{name: "negated wildcard", line: "*@example.org,!dev@example.org " + key, principal: "dev@example.org"},
+		{name: "git namespace", line: `dev@example.org namespaces="git" ` + key, principal: "dev@example.org", wantAllowed: true},
+		{name: "namespace list", line: `dev@example.org namespaces="file,git" ` + key, principal: "dev@example.org", wantAllowed: true},
+		{name: "other namespace", line: `dev@example.org namespaces="file" ` + key, principal: "dev@example.org"},
+		{name: "valid after", line: `dev@example.org valid-after="20240101" ` + key, principal: "dev@example.org", wantAllowed: true},
+		{name: "not yet valid", line: `dev@example.org valid-after="20240601130000Z" ` + key, principal: "dev@example.org"},
+		{name: "valid before", line: `dev@example.org valid-before="202406011300Z" ` + key, principal: "dev@example.org", wantAllowed: true},
+		{name: "expired", line: `dev@example.org namespaces="git",valid-before="20240101" ` + key, principal: "dev@example.org"},
+		{name: "cert authority", line: "*@example.org cert-authority " + key, wantUnsupported: true},
+		{name: "invalid time", line: `dev@example.org valid-after="2024" ` + key, wantParseErr: true},
+		{name: "missing key", line: "dev@example.org", wantParseErr: true},
+		{name: "unterminated quote", line: `"dev@example.org ` + key, wantParseErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer, err := parseAllowedSigner(tt.line)
+			if tt.wantUnsupported {
+				if !errors.Is(err, errUnsupportedOption) {
+					t.Fatalf("expected unsupported option, got %v", err)
+				}
+				return
+			}
+			if (err != nil) != tt.wantParseErr {
+				t.Fatalf("expected error %v, got %v", tt.wantParseErr, err)
+			}
+			if tt.wantParseErr {
+				return
+			}
+
+			err = signer.allows(tt.principal, sshSignatureNamespace, signedAt)
+			if (err == nil) != tt.wantAllowed {
+				t.Fatalf("expected allowed %v, got %v", tt.wantAllowed, err)
+			}
+		})
+	}
+}
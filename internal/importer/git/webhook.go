@@ -0,0 +1,177 @@
+package git
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	ctxPkg "github.com/eclipse-xfsc/microservice-core-go/pkg/ctx"
+	"github.com/gin-gonic/gin"
+)
+
+// maxWebhookSize is the limit of GitHub for push payloads
+const maxWebhookSize = 25 << 20
+
+var errUnknownWebhook = errors.New("request is no GitHub, GitLab or Gitea webhook")
+
+// webhookGroup returns the /v1 group for the webhook, which belongs to the repository instead of a tenant. The
+// server only hands out the tenant group (/v1/tenants/:tenantId), gin cleans the ".." of the relative path.
+func webhookGroup(tenants *gin.RouterGroup) *gin.RouterGroup {
+	return tenants.Group("/../..")
+}
+
+// WebhookHandler triggers a pull for push events of the tracked revision. Other events (e.g. ping) are
+// acknowledged without pull.
+func (g *Importer) WebhookHandler(c *gin.Context) {
+	log := ctxPkg.GetLogger(c)
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookSize))
+	if err != nil {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	push, err := g.verifyWebhook(c.Request.Header, body)
+	if err != nil {
+		log.Error(err, "rejected git webhook")
+
+		status := http.StatusUnauthorized
+		if errors.Is(err, errUnknownWebhook) {
+			status = http.StatusBadRequest
+		}
+		c.AbortWithStatus(status)
+		return
+	}
+
+	if !push {
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	var payload struct {
+		Ref string `json:"ref"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	if !g.tracks(payload.Ref) {
+		log.Debug("ignored push of untracked ref", "ref", payload.Ref)
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	now := time.Now()
+
+	g.mu.Lock()
+	g.status.LastWebhook = &now
+	g.mu.Unlock()
+
+	select {
+	case g.triggers <- struct{}{}:
+	default:
+		// a pull is already pending
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// verifyWebhook checks the signature (GitHub, Gitea) or token (GitLab) of the request and reports whether it
+// is a push event
+func (g *Importer) verifyWebhook(header http.Header, body []byte) (bool, error) {
+	secret := []byte(g.config.WebhookSecret)
+
+	// Gitea sends the GitHub headers as well
+	switch {
+	case header.Get("X-Gitea-Event") != "":
+		if !validSignature(header.Get("X-Gitea-Signature"), body, secret) {
+			return false, errors.New("invalid signature of gitea webhook")
+		}
+
+		return header.Get("X-Gitea-Event") == "push", nil
+	case header.Get("X-Gitlab-Event") != "":
+		if subtle.ConstantTimeCompare([]byte(header.Get("X-Gitlab-Token")), secret) != 1 {
+			return false, errors.New("invalid token of gitlab webhook")
+		}
+
+		event := header.Get("X-Gitlab-Event")
+		return event == "Push Hook" || event == "Tag Push Hook", nil
+	case header.Get("X-GitHub-Event") != "":
+		signature, _ := strings.CutPrefix(header.Get("X-Hub-Signature-256"), "sha256=")
+		if !validSignature(signature, body, secret) {
+			return false, errors.New("invalid signature of github webhook")
+		}
+
+		return header.Get("X-GitHub-Event") == "push", nil
+	default:
+		return false, errUnknownWebhook
+	}
+}
+
+// validSignature checks the hex encoded HMAC-SHA256 of the body
+func validSignature(signature string, body, secret []byte) bool {
+	decoded, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+
+	return hmac.Equal(decoded, mac.Sum(nil))
+}
+
+// tracks reports whether a push of the ref can change the checked out revision
+func (g *Importer) tracks(ref string) bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	switch {
+	case g.config.Commit != "":
+		// the pinned commit may not have been fetched yet
+		return g.status.Active != g.config.Commit
+	case g.config.Tag != "":
+		return ref == "refs/tags/"+g.config.Tag
+	case g.config.Branch != "":
+		return ref == "refs/heads/"+g.config.Branch
+	case g.defaultBranch == "":
+		// not cloned yet
+		return true
+	default:
+		return ref == "refs/heads/"+g.defaultBranch
+	}
+}
+
+// debounce pulls after a webhook. Pushes during the delay are covered by the same pull.
+func (g *Importer) debounce(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-g.triggers:
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(g.config.WebhookDebounce):
+		}
+
+		// pushes during the delay are part of the pull, pushes during the pull trigger the next one
+		select {
+		case <-g.triggers:
+		default:
+		}
+
+		_ = g.sync(ctx)
+	}
+}
@@ -0,0 +1,198 @@
+package git
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/config"
+)
+
+func sign(body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestValidSignature(t *testing.T) {
+	body := []byte(`{"ref":"refs/heads/main"}`)
+
+	tests := []struct {
+		name      string
+		signature string
+		want      bool
+	}{
+		{name: "valid", signature: sign(body, "secret"), want: true},
+		{name: "other secret", signature: sign(body, "other")},
+		{name: "other body", signature: sign([]byte(`{}`), "secret")},
+		{name: "truncated", signature: sign(body, "secret")[:32]},
+		{name: "not hex", signature: "zz"},
+		{name: "empty", signature: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validSignature(tt.signature, body, []byte("secret")); got != tt.want {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestVerifyWebhook(t *testing.T) {
+	body := []byte(`{"ref":"refs/heads/main"}`)
+	signature := sign(body, "secret")
+
+	tests := []struct {
+		name     string
+		header   map[string]string
+		wantPush bool
+		wantErr  bool
+		unknown  bool
+	}{
+		{
+			name:     "github push",
+			header:   map[string]string{"X-GitHub-Event": "push", "X-Hub-Signature-256": "sha256=" + signature},
+			wantPush: true,
+		},
+		{
+			name:   "github ping",
+			header: map[string]string{"X-GitHub-Event": "ping", "X-Hub-Signature-256": "sha256=" + signature},
+		},
+		{
+			name:    "github invalid signature",
+			header:  map[string]string{"X-GitHub-Event": "push", "X-Hub-Signature-256": "sha256=" + sign(body, "other")},
+			wantErr: true,
+		},
+		{
+			name:    "github without signature",
+			header:  map[string]string{"X-GitHub-Event": "push"},
+			wantErr: true,
+		},
+		{
+			name: "gitea push",
+			header: map[string]string{
+				"X-Gitea-Event": "push", "X-Gitea-Signature": signature,
+				"X-GitHub-Event": "push", "X-Hub-Signature-256": "sha256=" + signature,
+			},
+			wantPush: true,
+		},
+		{
+			name: "gitea ignores github signature",
+			header: map[string]string{
+				"X-Gitea-Event": "push", "X-Gitea-Signature": sign(body, "other"),
+				"X-GitHub-Event": "push", "X-Hub-Signature-256": "sha256=" + signature,
+			},
+			wantErr: true,
+		},
+		{
+			name:     "gitlab push",
+			header:   map[string]string{"X-Gitlab-Event": "Push Hook", "X-Gitlab-Token": "secret"},
+			wantPush: true,
+		},
+		{
+			name:     "gitlab tag push",
+			header:   map[string]string{"X-Gitlab-Event": "Tag Push Hook", "X-Gitlab-Token": "secret"},
+			wantPush: true,
+		},
+		{
+			name:   "gitlab merge request",
+			header: map[string]string{"X-Gitlab-Event": "Merge Request Hook", "X-Gitlab-Token": "secret"},
+		},
+		{
+			name:    "gitlab invalid token",
+			header:  map[string]string{"X-Gitlab-Event": "Push Hook", "X-Gitlab-Token": "other"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown",
+			header:  map[string]string{"X-Event": "push"},
+			wantErr: true,
+			unknown: true,
+		},
+	}
+
+	g := &Importer{config: config.GitConfig{WebhookSecret: "secret"}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for key, value := range tt.header {
+				header.Set(key, value)
+			}
+
+			push, err := g.verifyWebhook(header, body)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if errors.Is(err, errUnknownWebhook) != tt.unknown {
+				t.Fatalf("expected unknown webhook %v, got %v", tt.unknown, err)
+			}
+			if push != tt.wantPush {
+				t.Fatalf("expected push %v, got %v", tt.wantPush, push)
+			}
+		})
+	}
+}
+
+func TestTracks(t *testing.T) {
+	tests := []struct {
+		name   string
+		conf   config.GitConfig
+		branch string
+		ref    string
+		want   bool
+	}{
+		{name: "default branch", branch: "main", ref: "refs/heads/main", want: true},
+		{name: "other branch", branch: "main", ref: "refs/heads/feature"},
+		{name: "not cloned yet", ref: "refs/heads/feature", want: true},
+		{name: "branch", conf: config.GitConfig{Branch: "release"}, branch: "main", ref: "refs/heads/release", want: true},
+		{name: "tag", conf: config.GitConfig{Tag: "v1"}, ref: "refs/tags/v1", want: true},
+		{name: "branch of tag name", conf: config.GitConfig{Tag: "v1"}, ref: "refs/heads/v1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &Importer{config: tt.conf, defaultBranch: tt.branch}
+			if got := g.tracks(tt.ref); got != tt.want {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWebhookGroup(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	tenants := router.Group("/v1/tenants/:tenantId")
+	webhookGroup(tenants).POST("/git/webhook", func(c *gin.Context) {
+		c.Status(http.StatusAccepted)
+	})
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+	}{
+		{name: "global", path: "/v1/git/webhook", wantStatus: http.StatusAccepted},
+		{name: "below a tenant", path: "/v1/tenants/t1/git/webhook", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, tt.path, nil))
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, recorder.Code)
+			}
+		})
+	}
+}
@@ -3,12 +3,15 @@ package importer
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/eclipse-xfsc/oid4-vci-vp-library/model/credential"
+	"github.com/eclipse-xfsc/oid4-vci-vp-library/model/oauth"
 
 	serverPkg "github.com/eclipse-xfsc/microservice-core-go/pkg/server"
 
 	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/common"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/types"
 )
 
 var ErrNotFound = errors.New("not found")
@@ -18,4 +21,17 @@ type Importer interface {
 	Stop() error
 	GotErrors() bool
 	GetCredentialIssuerMetadata(ctx context.Context, tenantID string) (*credential.IssuerMetadata, error)
+	// GetCredentialIssuerLastModified returns the time the credential issuer metadata (including the credential
+	// configurations) was changed last. It is cheaper than loading the metadata.
+	GetCredentialIssuerLastModified(ctx context.Context, tenantID string) (time.Time, error)
+	// GetCredentialConfigurations returns the credential configurations of the tenant by id. Unlike
+	// GetCredentialIssuerMetadata, it doesn't require an issuer.
+	GetCredentialConfigurations(ctx context.Context, tenantID string) (map[string]credential.CredentialConfiguration, error)
+	GetAuthorizationServerMetadata(ctx context.Context, tenantID string) (*types.AuthorizationServerMetadata, error)
+	GetOpenIDConfiguration(ctx context.Context, tenantID string) (*oauth.OpenIdConfiguration, error)
+	GetVerifierMetadata(ctx context.Context, tenantID string) (*types.VerifierMetadata, error)
+	GetJwtVcIssuerMetadata(ctx context.Context, tenantID string) (*types.JwtVcIssuerMetadata, error)
+	// ListAssets returns the assets of the tenant by name. The content may be omitted.
+	ListAssets(ctx context.Context, tenantID string) (map[string]types.Asset, error)
+	GetAsset(ctx context.Context, tenantID, name string) (*types.Asset, error)
 }
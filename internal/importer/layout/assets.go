@@ -0,0 +1,141 @@
+package layout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/importer"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/types"
+)
+
+// ListAssets returns the assets of images/ of the tenant
+func (r *Reader) ListAssets(_ context.Context, tenantID string) (map[string]types.Asset, error) {
+	t, err := r.tenant(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.assets, nil
+}
+
+// GetAsset returns the asset of images/ of the tenant with the given name, e.g. logo.png or icons/logo.png
+func (r *Reader) GetAsset(_ context.Context, tenantID, name string) (*types.Asset, error) {
+	t, err := r.tenant(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	asset, ok := t.assets[name]
+	if !ok {
+		return nil, importer.ErrNotFound
+	}
+
+	return &asset, nil
+}
+
+// loadAssets reads the files of images/ of the tenant, which are named by their slash separated path below
+// images/. Files which are too large or link outside of images/, and an images/ which links outside of the
+// tenant are reported by Validate and not served.
+func loadAssets(tenantPath string) (map[string]types.Asset, []error, time.Time, error) {
+	var lastModified time.Time
+
+	root, err := filepath.EvalSymlinks(assemblePath(tenantPath, ImagesDir))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil, lastModified, nil
+		}
+
+		return nil, nil, lastModified, err
+	}
+
+	// a linked images/ would serve any directory of the host, e.g. /etc
+	tenantRoot, err := filepath.EvalSymlinks(tenantPath)
+	if err != nil {
+		return nil, nil, lastModified, err
+	}
+
+	if !strings.HasPrefix(root, tenantRoot+string(filepath.Separator)) {
+		return nil, []error{fmt.Errorf("%s links outside of the tenant", ImagesDir)}, lastModified, nil
+	}
+
+	assets := make(map[string]types.Asset)
+
+	var assetErrs []error
+	err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if path == root {
+			return nil
+		}
+
+		// hidden entries are version control or volume internals (..data)
+		if entry.Name()[0] == '.' {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if entry.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+
+		// files of mounted volumes are symlinks into a hidden directory of images/
+		target, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			assetErrs = append(assetErrs, fmt.Errorf("asset %s: %w", name, err))
+			return nil
+		}
+
+		if !strings.HasPrefix(target, root+string(filepath.Separator)) {
+			assetErrs = append(assetErrs, fmt.Errorf("asset %s links outside of %s", name, ImagesDir))
+			return nil
+		}
+
+		info, err := os.Stat(target)
+		if err != nil {
+			return err
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		if info.Size() > types.MaxAssetSize {
+			assetErrs = append(assetErrs, fmt.Errorf("asset %s exceeds %d bytes", name, types.MaxAssetSize))
+			return nil
+		}
+
+		content, err := os.ReadFile(target)
+		if err != nil {
+			return err
+		}
+
+		assets[name] = types.NewAsset(name, content, info.ModTime())
+		if info.ModTime().After(lastModified) {
+			lastModified = info.ModTime()
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, nil, lastModified, err
+	}
+
+	return assets, assetErrs, lastModified, nil
+}
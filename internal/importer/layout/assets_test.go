@@ -0,0 +1,112 @@
+package layout
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestLoadAssets(t *testing.T) {
+	tests := []struct {
+		name       string
+		setup      func(t *testing.T, tenant, outside string)
+		wantAssets []string
+		wantErrs   int
+	}{
+		{
+			name:  "without images",
+			setup: func(t *testing.T, tenant, outside string) {},
+		},
+		{
+			name: "files",
+			setup: func(t *testing.T, tenant, outside string) {
+				write(t, filepath.Join(tenant, ImagesDir, "logo.png"), "logo")
+				write(t, filepath.Join(tenant, ImagesDir, "icons", "small.png"), "small")
+				write(t, filepath.Join(tenant, ImagesDir, ".git", "config"), "hidden")
+			},
+			wantAssets: []string{"icons/small.png", "logo.png"},
+		},
+		{
+			name: "volume links",
+			setup: func(t *testing.T, tenant, outside string) {
+				write(t, filepath.Join(tenant, ImagesDir, "..data", "logo.png"), "logo")
+				link(t, filepath.Join("..data", "logo.png"), filepath.Join(tenant, ImagesDir, "logo.png"))
+			},
+			wantAssets: []string{"logo.png"},
+		},
+		{
+			name: "file linked outside",
+			setup: func(t *testing.T, tenant, outside string) {
+				write(t, filepath.Join(outside, "passwd"), "secret")
+				write(t, filepath.Join(tenant, ImagesDir, "logo.png"), "logo")
+				link(t, filepath.Join(outside, "passwd"), filepath.Join(tenant, ImagesDir, "passwd.png"))
+			},
+			wantAssets: []string{"logo.png"},
+			wantErrs:   1,
+		},
+		{
+			name: "images linked outside",
+			setup: func(t *testing.T, tenant, outside string) {
+				write(t, filepath.Join(outside, "passwd"), "secret")
+				link(t, outside, filepath.Join(tenant, ImagesDir))
+			},
+			wantErrs: 1,
+		},
+		{
+			name: "images linked within tenant",
+			setup: func(t *testing.T, tenant, outside string) {
+				write(t, filepath.Join(tenant, "logos", "logo.png"), "logo")
+				link(t, "logos", filepath.Join(tenant, ImagesDir))
+			},
+			wantAssets: []string{"logo.png"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tenant, outside := t.TempDir(), t.TempDir()
+			tt.setup(t, tenant, outside)
+
+			assets, errs, _, err := loadAssets(tenant)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var names []string
+			for name := range assets {
+				names = append(names, name)
+			}
+			slices.Sort(names)
+
+			if !slices.Equal(names, tt.wantAssets) {
+				t.Fatalf("expected assets %v, got %v", tt.wantAssets, names)
+			}
+			if len(errs) != tt.wantErrs {
+				t.Fatalf("expected %d errors, got %v", tt.wantErrs, errs)
+			}
+		})
+	}
+}
+
+func write(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func link(t *testing.T, target, path string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, path); err != nil {
+		t.Fatal(err)
+	}
+}
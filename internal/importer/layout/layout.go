@@ -0,0 +1,386 @@
+package layout
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-library/model/credential"
+	"github.com/eclipse-xfsc/oid4-vci-vp-library/model/oauth"
+
+	logPkg "github.com/eclipse-xfsc/microservice-core-go/pkg/logr"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/importer"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/signer"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/types"
+)
+
+const (
+	IssuerJSON              = "issuer.json"
+	IssuerYAML              = "issuer.yaml"
+	AuthorizationServerJSON = "authorization-server.json"
+	OpenIDConfigurationJSON = "openid-configuration.json"
+	VerifierJSON            = "verifier.json"
+	JwtVcIssuerJSON         = "jwt-vc-issuer.json"
+	CredentialsSupportedDir = "credentials"
+	// CredentialsYAML contains credential configurations keyed by their id, next to the files in credentials/
+	CredentialsYAML = "credentials.yaml"
+)
+
+// Reader serves the metadata of the tenants from a directory with one folder per tenant:
+//
+//	tenant-id/
+//	├── issuer.json (or issuer.yaml)
+//	├── credentials.yaml
+//	├── authorization-server.json
+//	├── openid-configuration.json
+//	├── verifier.json
+//	├── jwt-vc-issuer.json
+//	├── images/
+//	│   └── <asset>
+//	└── credentials/
+//	    └── <configuration id>.json (or .yaml)
+//
+// The directory is read into a Snapshot by Load, requests are served from the active snapshot in memory.
+// It is shared by the importers which read this layout from disk.
+type Reader struct {
+	folder   string
+	log      logPkg.Logger
+	signed   *signer.Cache
+	snapshot atomic.Pointer[Snapshot]
+}
+
+// NewReader creates a reader for the given directory. If a signer is given, signed_metadata of the directory is
+// replaced by a JWT signed with the key of the tenant.
+func NewReader(folder string, metadataSigner *signer.Signer, logger logPkg.Logger) *Reader {
+	r := &Reader{
+		folder: folder,
+		log:    logger,
+	}
+
+	if metadataSigner != nil {
+		r.signed = signer.NewCache(metadataSigner)
+	}
+
+	return r
+}
+
+func (r *Reader) Folder() string {
+	return r.folder
+}
+
+// Activate replaces the served snapshot. Requests in progress keep the previous one.
+func (r *Reader) Activate(snapshot *Snapshot) {
+	r.snapshot.Store(snapshot)
+}
+
+// Tenants returns the sorted ids of the tenants of the active snapshot
+func (r *Reader) Tenants() []string {
+	snapshot := r.snapshot.Load()
+	if snapshot == nil {
+		return nil
+	}
+
+	return snapshot.Tenants()
+}
+
+// UsesTemplates reports whether a file of the tenant is a template, which depends on the origin it is
+// rendered for
+func (r *Reader) UsesTemplates(tenantID string) bool {
+	t, err := r.tenant(tenantID)
+	if err != nil {
+		return false
+	}
+
+	if t.issuer.isTemplate() || t.authorizationServer.isTemplate() || t.openIDConfiguration.isTemplate() ||
+		t.verifier.isTemplate() || t.jwtVcIssuer.isTemplate() {
+		return true
+	}
+
+	for _, configuration := range t.credentials {
+		if configuration.isTemplate() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *Reader) tenant(tenantID string) (*tenant, error) {
+	snapshot := r.snapshot.Load()
+	if snapshot == nil {
+		return nil, importer.ErrNotFound
+	}
+
+	t, ok := snapshot.tenants[tenantID]
+	if !ok {
+		return nil, importer.ErrNotFound
+	}
+
+	return t, nil
+}
+
+// GetCredentialIssuerMetadata renders and decodes issuer.json and the credential configurations of the tenant.
+// The template data is taken from the context (see types.WithTemplateData).
+func (r *Reader) GetCredentialIssuerMetadata(ctx context.Context, tenantID string) (*credential.IssuerMetadata, error) {
+	t, err := r.tenant(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.issuer == nil {
+		return nil, importer.ErrNotFound
+	}
+
+	data := templateData(ctx, tenantID)
+
+	credentials, err := r.collectCredentialsSupported(t, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collectCredentialsSupported: %w", err)
+	}
+
+	encoded, err := json.Marshal(credentials)
+	if err != nil {
+		return nil, err
+	}
+	data.Credentials = string(encoded)
+
+	issuer, err := t.issuer.get(t.assets, data)
+	if err != nil {
+		return nil, err
+	}
+
+	// the copy shares the arrays of the snapshot, which the gateway appends to
+	issuer.AuthorizationServers = slices.Clone(issuer.AuthorizationServers)
+	issuer.CredentialConfigurationsSupported = credentials
+
+	if r.signed != nil {
+		if err := r.signed.SignIssuerMetadata(ctx, tenantID, issuer); err != nil {
+			return nil, err
+		}
+	}
+
+	return issuer, nil
+}
+
+// GetCredentialConfigurations renders and decodes the credential configurations of the tenant. Broken
+// configurations are skipped like in GetCredentialIssuerMetadata.
+func (r *Reader) GetCredentialConfigurations(ctx context.Context, tenantID string) (map[string]credential.CredentialConfiguration, error) {
+	t, err := r.tenant(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials, err := r.collectCredentialsSupported(t, templateData(ctx, tenantID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to collectCredentialsSupported: %w", err)
+	}
+
+	return credentials, nil
+}
+
+// GetCredentialIssuerLastModified returns the latest modification time of issuer.json and the credential
+// configurations of the tenant
+func (r *Reader) GetCredentialIssuerLastModified(_ context.Context, tenantID string) (time.Time, error) {
+	t, err := r.tenant(tenantID)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if t.issuer == nil {
+		return time.Time{}, importer.ErrNotFound
+	}
+
+	return t.lastModified, nil
+}
+
+func (r *Reader) GetAuthorizationServerMetadata(_ context.Context, tenantID string) (*types.AuthorizationServerMetadata, error) {
+	t, err := r.tenant(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.authorizationServer.get(t.assets, types.TemplateData{})
+}
+
+func (r *Reader) GetOpenIDConfiguration(_ context.Context, tenantID string) (*oauth.OpenIdConfiguration, error) {
+	t, err := r.tenant(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.openIDConfiguration.get(t.assets, types.TemplateData{})
+}
+
+func (r *Reader) GetVerifierMetadata(_ context.Context, tenantID string) (*types.VerifierMetadata, error) {
+	t, err := r.tenant(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.verifier.get(t.assets, types.TemplateData{})
+}
+
+// GetJwtVcIssuerMetadata returns the jwt-vc-issuer.json of the tenant. A missing issuer is taken from issuer.json.
+func (r *Reader) GetJwtVcIssuerMetadata(ctx context.Context, tenantID string) (*types.JwtVcIssuerMetadata, error) {
+	t, err := r.tenant(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := t.jwtVcIssuer.get(t.assets, types.TemplateData{})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := metadata.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", JwtVcIssuerJSON, err)
+	}
+
+	if metadata.Issuer == "" {
+		issuer, err := t.issuer.get(t.assets, templateData(ctx, tenantID))
+		if err != nil {
+			return nil, err
+		}
+
+		metadata.Issuer = issuer.CredentialIssuer
+	}
+
+	return metadata, nil
+}
+
+// Validate decodes the metadata of all tenants of the snapshot and checks the fields required by the
+// specifications. It returns the joined errors. Unlike the getters, which skip broken credential
+// configurations, every file has to be valid.
+func (r *Reader) Validate(snapshot *Snapshot) error {
+	var errs []error
+	for _, tenantID := range snapshot.Tenants() {
+		for _, err := range r.validateTenant(tenantID, snapshot.tenants[tenantID]) {
+			errs = append(errs, fmt.Errorf("%s: %w", tenantID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (r *Reader) validateTenant(tenantID string, t *tenant) []error {
+	var errs []error
+	check := func(err error) {
+		if err != nil && !errors.Is(err, importer.ErrNotFound) {
+			errs = append(errs, err)
+		}
+	}
+
+	data := templateData(context.Background(), tenantID)
+
+	issuer, err := t.issuer.get(t.assets, data)
+	check(err)
+	if err == nil {
+		if err := types.ValidateIssuerMetadata(issuer); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", t.issuer.name, err))
+		}
+
+		for _, logo := range types.MissingLogos(issuer.Display, t.assets) {
+			errs = append(errs, fmt.Errorf("%s: logo %s not found in %s", t.issuer.name, logo, ImagesDir))
+		}
+	}
+
+	jwtVcIssuer, err := t.jwtVcIssuer.get(t.assets, data)
+	check(err)
+	if err == nil {
+		if err := jwtVcIssuer.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", JwtVcIssuerJSON, err))
+		}
+	}
+
+	authorizationServer, err := t.authorizationServer.get(t.assets, data)
+	check(err)
+	if err == nil {
+		if err := authorizationServer.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", AuthorizationServerJSON, err))
+		}
+	}
+
+	verifier, err := t.verifier.get(t.assets, data)
+	check(err)
+	if err == nil {
+		if err := verifier.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", VerifierJSON, err))
+		}
+	}
+
+	_, err = t.openIDConfiguration.get(t.assets, data)
+	check(err)
+
+	for _, id := range sortedKeys(t.credentials) {
+		configuration, err := t.credentials[id].get(t.assets, data)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if err := types.ValidateCredentialConfiguration(configuration); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", id, err))
+		}
+
+		for _, logo := range types.MissingLogos(configuration.Display, t.assets) {
+			errs = append(errs, fmt.Errorf("%s: logo %s not found in %s", id, logo, ImagesDir))
+		}
+	}
+
+	return append(errs, t.assetErrs...)
+}
+
+// decode renders the template and decodes the JSON or YAML result into v
+func decode(name string, content []byte, fromYAML bool, data types.TemplateData, assets map[string]types.Asset, v any) error {
+	rendered, err := render(name, content, data, assets)
+	if err != nil {
+		return err
+	}
+
+	if err := unmarshal(rendered, fromYAML, v); err != nil {
+		if !bytes.Equal(rendered, content) {
+			format := "JSON"
+			if fromYAML {
+				format = "YAML"
+			}
+
+			return fmt.Errorf("template %s rendered invalid %s: %w", name, format, err)
+		}
+
+		return fmt.Errorf("failed to decode %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// collectCredentialsSupported decodes the credential configurations of the tenant. Files which can't be
+// decoded are skipped, but templates which fail to render or render invalid JSON fail the whole collection.
+func (r *Reader) collectCredentialsSupported(t *tenant, data types.TemplateData) (map[string]credential.CredentialConfiguration, error) {
+	credentials := make(map[string]credential.CredentialConfiguration, len(t.credentials))
+	for id, document := range t.credentials {
+		configuration, err := document.get(t.assets, data)
+		if err != nil {
+			if document.template {
+				return nil, err
+			}
+
+			r.log.Error(err, "failed to unmarshal credentials supported")
+			continue
+		}
+
+		credentials[id] = *configuration
+	}
+
+	return credentials, nil
+}
+
+func assemblePath(paths ...string) string {
+	return filepath.Join(paths...)
+}
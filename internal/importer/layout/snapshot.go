@@ -0,0 +1,364 @@
+package layout
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-library/model/credential"
+	"github.com/eclipse-xfsc/oid4-vci-vp-library/model/oauth"
+	"gopkg.in/yaml.v3"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/importer"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/types"
+)
+
+// Snapshot is the immutable content of a directory in the layout. Files without template actions are decoded
+// once when the snapshot is loaded, templates are rendered for every request.
+type Snapshot struct {
+	tenants map[string]*tenant
+}
+
+type tenant struct {
+	issuer              *document[credential.IssuerMetadata]
+	credentials         map[string]*document[credential.CredentialConfiguration]
+	authorizationServer *document[types.AuthorizationServerMetadata]
+	openIDConfiguration *document[oauth.OpenIdConfiguration]
+	verifier            *document[types.VerifierMetadata]
+	jwtVcIssuer         *document[types.JwtVcIssuerMetadata]
+	assets              map[string]types.Asset
+	assetErrs           []error
+	lastModified        time.Time
+}
+
+// document is a file of a tenant in JSON or YAML. A nil document is a missing file.
+type document[T any] struct {
+	name     string
+	content  []byte
+	template bool
+	yaml     bool
+	value    *T
+	err      error
+}
+
+// Tenants returns the sorted ids of the tenants in the snapshot
+func (s *Snapshot) Tenants() []string {
+	return sortedKeys(s.tenants)
+}
+
+// Load reads the tenants of the directory into a new snapshot, which is served after Activate. Files which
+// can't be decoded are kept and reported by the getters and Validate, only unreadable files fail the load.
+func (r *Reader) Load(folder string) (*Snapshot, error) {
+	entries, err := os.ReadDir(folder)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &Snapshot{tenants: make(map[string]*tenant)}
+	for _, entry := range entries {
+		// hidden entries are version control or volume internals (.git, ..data)
+		if entry.Name()[0] == '.' {
+			continue
+		}
+
+		// tenants of mounted volumes are symlinks, so the entry itself is not a directory
+		tenantPath := assemblePath(folder, entry.Name())
+		if info, err := os.Stat(tenantPath); err != nil || !info.IsDir() {
+			continue
+		}
+
+		t, err := loadTenant(tenantPath)
+		if err != nil {
+			r.log.Error(err, "failed to read tenant from disk", "tenant", entry.Name())
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+
+		snapshot.tenants[entry.Name()] = t
+	}
+
+	return snapshot, nil
+}
+
+func loadTenant(tenantPath string) (*tenant, error) {
+	t := &tenant{credentials: make(map[string]*document[credential.CredentialConfiguration])}
+
+	var err error
+
+	if t.issuer, t.lastModified, err = loadIssuer(tenantPath); err != nil {
+		return nil, err
+	}
+
+	if t.authorizationServer, _, err = loadDocument[types.AuthorizationServerMetadata](tenantPath, AuthorizationServerJSON, false); err != nil {
+		return nil, err
+	}
+
+	if t.openIDConfiguration, _, err = loadDocument[oauth.OpenIdConfiguration](tenantPath, OpenIDConfigurationJSON, false); err != nil {
+		return nil, err
+	}
+
+	if t.verifier, _, err = loadDocument[types.VerifierMetadata](tenantPath, VerifierJSON, false); err != nil {
+		return nil, err
+	}
+
+	if t.jwtVcIssuer, _, err = loadDocument[types.JwtVcIssuerMetadata](tenantPath, JwtVcIssuerJSON, false); err != nil {
+		return nil, err
+	}
+
+	credentialsPath := assemblePath(tenantPath, CredentialsSupportedDir)
+
+	files, err := os.ReadDir(credentialsPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+
+		configuration, modTime, err := loadDocument[credential.CredentialConfiguration](credentialsPath, file.Name(), true)
+		if err != nil {
+			return nil, err
+		}
+
+		if configuration == nil {
+			continue
+		}
+
+		id := configurationID(file.Name())
+		if existing, ok := t.credentials[id]; ok {
+			configuration = &document[credential.CredentialConfiguration]{
+				name: id,
+				err:  fmt.Errorf("credential configuration %s is defined in %s and %s", id, existing.name, file.Name()),
+			}
+		}
+
+		t.credentials[id] = configuration
+		if modTime.After(t.lastModified) {
+			t.lastModified = modTime
+		}
+	}
+
+	configurations, modTime, err := loadCredentialsYAML(tenantPath)
+	if err != nil {
+		return nil, err
+	}
+
+	for id, configuration := range configurations {
+		if _, ok := t.credentials[id]; ok {
+			configuration = &document[credential.CredentialConfiguration]{
+				name: id,
+				err:  fmt.Errorf("credential configuration %s is defined in %s and %s", id, CredentialsSupportedDir, CredentialsYAML),
+			}
+		}
+
+		t.credentials[id] = configuration
+	}
+
+	if modTime.After(t.lastModified) {
+		t.lastModified = modTime
+	}
+
+	// the rendered metadata links the assets by their content
+	if t.assets, t.assetErrs, modTime, err = loadAssets(tenantPath); err != nil {
+		return nil, err
+	}
+
+	if modTime.After(t.lastModified) {
+		t.lastModified = modTime
+	}
+
+	return t, nil
+}
+
+// configurationID is the id of a credential configuration in the credentials directory, which is the file name
+// without .json, .yaml or .yml, like the keys of credentials.yaml
+func configurationID(name string) string {
+	if ext := filepath.Ext(name); ext == ".json" || isYAML(name) {
+		return strings.TrimSuffix(name, ext)
+	}
+
+	return name
+}
+
+// loadIssuer reads issuer.json or issuer.yaml of the tenant, of which only one may exist
+func loadIssuer(tenantPath string) (*document[credential.IssuerMetadata], time.Time, error) {
+	issuerJSON, jsonModTime, err := loadDocument[credential.IssuerMetadata](tenantPath, IssuerJSON, true)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	issuerYAML, yamlModTime, err := loadDocument[credential.IssuerMetadata](tenantPath, IssuerYAML, true)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	switch {
+	case issuerYAML == nil:
+		return issuerJSON, jsonModTime, nil
+	case issuerJSON == nil:
+		return issuerYAML, yamlModTime, nil
+	default:
+		return &document[credential.IssuerMetadata]{
+			name: IssuerJSON,
+			err:  fmt.Errorf("only one of %s and %s may exist", IssuerJSON, IssuerYAML),
+		}, jsonModTime, nil
+	}
+}
+
+// loadCredentialsYAML reads the credential configurations of credentials.yaml, which are keyed by their id.
+// Every configuration is a document of its own, so template actions have to be quoted to keep the file valid
+// YAML before rendering. A file which can't be split is kept as a broken configuration named like the file.
+func loadCredentialsYAML(tenantPath string) (map[string]*document[credential.CredentialConfiguration], time.Time, error) {
+	path := assemblePath(tenantPath, CredentialsYAML)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, time.Time{}, nil
+		}
+
+		return nil, time.Time{}, err
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	broken := func(err error) map[string]*document[credential.CredentialConfiguration] {
+		return map[string]*document[credential.CredentialConfiguration]{
+			CredentialsYAML: {name: CredentialsYAML, err: fmt.Errorf("failed to decode %s: %w", CredentialsYAML, err)},
+		}
+	}
+
+	root, err := decodeYAML(content)
+	if err != nil {
+		return broken(err), info.ModTime(), nil
+	}
+
+	// an empty file has no content
+	if len(root.Content) == 0 {
+		return nil, info.ModTime(), nil
+	}
+
+	mapping := root.Content[0]
+	if mapping.Kind != yaml.MappingNode {
+		return broken(errors.New("configurations have to be keyed by their id")), info.ModTime(), nil
+	}
+
+	budget := maxYAMLNodes
+	configurations := make(map[string]*document[credential.CredentialConfiguration], len(mapping.Content)/2)
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		id := mapping.Content[i].Value
+
+		// hidden entries only hold anchors shared by the configurations
+		if strings.HasPrefix(id, ".") {
+			continue
+		}
+
+		if _, ok := configurations[id]; ok {
+			return broken(fmt.Errorf("line %d: credential configuration %s is defined twice", mapping.Content[i].Line, id)), info.ModTime(), nil
+		}
+
+		// the anchors are defined outside the document of the configuration
+		resolved, err := resolveAliases(mapping.Content[i+1], &budget)
+		if err != nil {
+			return broken(err), info.ModTime(), nil
+		}
+
+		encoded, err := yaml.Marshal(resolved)
+		if err != nil {
+			return broken(err), info.ModTime(), nil
+		}
+
+		configurations[id] = newDocument[credential.CredentialConfiguration](CredentialsYAML+"#"+id, encoded, true, true)
+	}
+
+	return configurations, info.ModTime(), nil
+}
+
+// loadDocument reads the file and, unless it is a template, decodes it. Missing files return a nil document.
+func loadDocument[T any](dir, name string, templates bool) (*document[T], time.Time, error) {
+	path := assemblePath(dir, name)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, time.Time{}, nil
+		}
+
+		return nil, time.Time{}, err
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	return newDocument[T](name, content, templates, isYAML(name)), info.ModTime(), nil
+}
+
+// newDocument decodes the content, unless it is a template
+func newDocument[T any](name string, content []byte, templates, fromYAML bool) *document[T] {
+	d := &document[T]{
+		name:     name,
+		content:  content,
+		template: templates && bytes.Contains(content, templateStart),
+		yaml:     fromYAML,
+	}
+
+	if !d.template {
+		var value T
+		if err := unmarshal(content, fromYAML, &value); err != nil {
+			d.err = fmt.Errorf("failed to decode %s: %w", name, err)
+		} else {
+			d.value = &value
+		}
+	}
+
+	return d
+}
+
+// isTemplate reports whether the document is rendered for every request; missing documents are none
+func (d *document[T]) isTemplate() bool {
+	return d != nil && d.template
+}
+
+// get returns a copy of the decoded document. Templates are rendered with the given data and assets.
+func (d *document[T]) get(assets map[string]types.Asset, data types.TemplateData) (*T, error) {
+	if d == nil {
+		return nil, importer.ErrNotFound
+	}
+
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	if !d.template {
+		value := *d.value
+		return &value, nil
+	}
+
+	var value T
+	if err := decode(d.name, d.content, d.yaml, data, assets, &value); err != nil {
+		return nil, err
+	}
+
+	return &value, nil
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
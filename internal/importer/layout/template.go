@@ -0,0 +1,81 @@
+package layout
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"text/template"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/types"
+)
+
+// ImagesDir contains the assets of a tenant, which are linked with the image template function or relative
+// logo URLs
+const ImagesDir = "images"
+
+// exampleOrigin is used to render the templates during validation, where no request is available
+const exampleOrigin = "https://example.org"
+
+var templateStart = []byte("{{")
+
+// render executes the file as Go template. Files without actions are returned as they are.
+//
+// Templates can use the fields of types.TemplateData and the functions
+//
+//	urlJoin "https://example.org" "path" "to"   https://example.org/path/to
+//	image "logo.png"                             content addressed URL of images/logo.png of the tenant
+//	json .BaseURL                                value as JSON, e.g. a quoted and escaped string
+//
+// The request derived fields are validated by the gateway, values of other sources should be written with json.
+func render(name string, content []byte, data types.TemplateData, assets map[string]types.Asset) ([]byte, error) {
+	if !bytes.Contains(content, templateStart) {
+		return content, nil
+	}
+
+	tmpl, err := template.New(name).
+		Option("missingkey=error").
+		Funcs(template.FuncMap{
+			"urlJoin": url.JoinPath,
+			"json": func(v any) (string, error) {
+				encoded, err := json.Marshal(v)
+				return string(encoded), err
+			},
+			"image": func(image string) (string, error) {
+				asset, ok := assets[image]
+				if !ok {
+					return "", fmt.Errorf("image %s not found in %s", image, ImagesDir)
+				}
+
+				return asset.URL(data.BaseURL)
+			},
+		}).
+		Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render template %s: %w", name, err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// templateData returns the template data of the request or, without request, data of an example origin
+func templateData(ctx context.Context, tenantID string) types.TemplateData {
+	data, ok := types.TemplateDataFromContext(ctx)
+	if !ok {
+		data = types.TemplateData{
+			Origin:  exampleOrigin,
+			BaseURL: exampleOrigin,
+		}
+	}
+
+	data.TenantId = tenantID
+	data.Credentials = "{}"
+
+	return data
+}
@@ -0,0 +1,49 @@
+package layout
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/types"
+)
+
+func TestRender(t *testing.T) {
+	data := types.TemplateData{
+		Origin:   "https://issuer.example",
+		TenantId: `tenant "a"`,
+		BaseURL:  "https://issuer.example/v1/tenants/a",
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+		wantErr  bool
+	}{
+		{name: "without actions", template: `{"a":"{ {"}`, want: `{"a":"{ {"}`},
+		{name: "origin", template: `{"credential_issuer":"{{ .Origin }}"}`, want: `{"credential_issuer":"https://issuer.example"}`},
+		{name: "url join", template: `{"credential_endpoint":"{{ urlJoin .BaseURL "credential" }}"}`, want: `{"credential_endpoint":"https://issuer.example/v1/tenants/a/credential"}`},
+		{name: "json escapes", template: `{"name":{{ json .TenantId }}}`, want: `{"name":"tenant \"a\""}`},
+		{name: "missing image", template: `{{ image "logo.png" }}`, wantErr: true},
+		{name: "unknown field", template: `{{ .Unknown }}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := render(tt.name, []byte(tt.template), data, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if string(got) != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+			if !json.Valid(got) {
+				t.Fatalf("rendered invalid JSON %s", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package layout
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxYAMLNodes limits the nodes of a YAML document after the expansion of aliases, so nested aliases can't
+// expand a small file into an exponentially large document ("billion laughs")
+const maxYAMLNodes = 100000
+
+var errYAMLTooLarge = fmt.Errorf("document has more than %d nodes after expanding aliases", maxYAMLNodes)
+
+// isYAML reports whether the file is YAML instead of JSON
+func isYAML(name string) bool {
+	ext := filepath.Ext(name)
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// unmarshal decodes the JSON or YAML content into v
+func unmarshal(content []byte, fromYAML bool, v any) error {
+	if fromYAML {
+		var err error
+		if content, err = yamlToJSON(content, reflect.TypeOf(v)); err != nil {
+			return err
+		}
+	}
+
+	return json.Unmarshal(content, v)
+}
+
+// yamlToJSON converts the YAML content to JSON for decoding into the given type, so the YAML keys are the JSON
+// names of the fields and the custom decoding of the type applies.
+func yamlToJSON(content []byte, t reflect.Type) ([]byte, error) {
+	node, err := decodeYAML(content)
+	if err != nil {
+		return nil, err
+	}
+
+	budget := maxYAMLNodes
+	value, err := convertYAML(node, t, &budget)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(value)
+}
+
+// decodeYAML parses the content, which must not contain more than one document
+func decodeYAML(content []byte) (*yaml.Node, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+
+	var node yaml.Node
+	if err := decoder.Decode(&node); err != nil {
+		if errors.Is(err, io.EOF) {
+			// an empty file is an empty document
+			return &yaml.Node{Kind: yaml.DocumentNode}, nil
+		}
+
+		return nil, err
+	}
+
+	var next yaml.Node
+	if err := decoder.Decode(&next); !errors.Is(err, io.EOF) {
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, fmt.Errorf("line %d: only one document is allowed", next.Line)
+	}
+
+	return &node, nil
+}
+
+// spend counts the node against the budget of the document
+func spend(budget *int) error {
+	*budget--
+	if *budget < 0 {
+		return errYAMLTooLarge
+	}
+
+	return nil
+}
+
+// convertYAML converts the node into a value of encoding/json. Scalars are converted by the type of the field
+// they are decoded into, so e.g. an unquoted version 1.0 or color 123456 stays a string.
+func convertYAML(node *yaml.Node, t reflect.Type, budget *int) (any, error) {
+	if err := spend(budget); err != nil {
+		return nil, err
+	}
+
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil, nil
+		}
+
+		return convertYAML(node.Content[0], t, budget)
+	case yaml.AliasNode:
+		return convertYAML(node.Alias, t, budget)
+	case yaml.MappingNode:
+		m := make(map[string]any, len(node.Content)/2)
+		if err := convertMapping(node, t, m, budget); err != nil {
+			return nil, err
+		}
+
+		return m, nil
+	case yaml.SequenceNode:
+		var elem reflect.Type
+		if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			elem = t.Elem()
+		}
+
+		s := make([]any, 0, len(node.Content))
+		for _, item := range node.Content {
+			value, err := convertYAML(item, elem, budget)
+			if err != nil {
+				return nil, err
+			}
+
+			s = append(s, value)
+		}
+
+		return s, nil
+	default:
+		if node.Tag == "!!null" {
+			return nil, nil
+		}
+
+		// JSON has no timestamps, they are kept as written
+		if node.Tag == "!!timestamp" || (t != nil && t.Kind() == reflect.String) {
+			return node.Value, nil
+		}
+
+		var value any
+		if err := node.Decode(&value); err != nil {
+			return nil, err
+		}
+
+		return value, nil
+	}
+}
+
+// convertMapping adds the keys of the mapping to m. Merged mappings (<<: *anchor) don't override the keys of
+// the mapping itself.
+func convertMapping(node *yaml.Node, t reflect.Type, m map[string]any, budget *int) error {
+	var merged []*yaml.Node
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+
+		if key.Tag == "!!merge" {
+			merged = append(merged, value)
+			continue
+		}
+
+		if key.Kind != yaml.ScalarNode {
+			return fmt.Errorf("line %d: key has to be a string", key.Line)
+		}
+
+		converted, err := convertYAML(value, fieldType(t, key.Value), budget)
+		if err != nil {
+			return err
+		}
+
+		m[key.Value] = converted
+	}
+
+	for _, value := range merged {
+		if value.Kind == yaml.AliasNode {
+			value = value.Alias
+		}
+
+		sources := []*yaml.Node{value}
+		if value.Kind == yaml.SequenceNode {
+			sources = value.Content
+		}
+
+		for _, source := range sources {
+			if source.Kind == yaml.AliasNode {
+				source = source.Alias
+			}
+
+			if source.Kind != yaml.MappingNode {
+				return fmt.Errorf("line %d: only mappings can be merged", source.Line)
+			}
+
+			defaults := make(map[string]any)
+			if err := convertMapping(source, t, defaults, budget); err != nil {
+				return err
+			}
+
+			for k, v := range defaults {
+				if _, ok := m[k]; !ok {
+					m[k] = v
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// resolveAliases returns a copy of the node in which aliases are replaced by copies of their anchored nodes.
+// Every copied node is counted against the budget.
+func resolveAliases(node *yaml.Node, budget *int) (*yaml.Node, error) {
+	if err := spend(budget); err != nil {
+		return nil, err
+	}
+
+	if node.Kind == yaml.AliasNode {
+		return resolveAliases(node.Alias, budget)
+	}
+
+	resolved := *node
+	resolved.Anchor = ""
+	resolved.Content = make([]*yaml.Node, len(node.Content))
+	for i, child := range node.Content {
+		var err error
+		if resolved.Content[i], err = resolveAliases(child, budget); err != nil {
+			return nil, err
+		}
+	}
+
+	return &resolved, nil
+}
+
+// fieldType returns the type of the value of the key in maps and structs, matched by the JSON name of the
+// fields. It is nil for unknown keys, whose values are converted as they are.
+func fieldType(t reflect.Type, key string) reflect.Type {
+	if t == nil {
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Map:
+		return t.Elem()
+	case reflect.Struct:
+		for i := range t.NumField() {
+			field := t.Field(i)
+
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+
+			if field.Anonymous && name == "" {
+				embedded := field.Type
+				if embedded.Kind() == reflect.Pointer {
+					embedded = embedded.Elem()
+				}
+
+				if ft := fieldType(embedded, key); ft != nil {
+					return ft
+				}
+
+				continue
+			}
+
+			if name == "" {
+				name = field.Name
+			}
+
+			// like encoding/json
+			if strings.EqualFold(name, key) {
+				return field.Type
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package layout
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type yamlTestDocument struct {
+	Version string            `json:"version"`
+	Order   int               `json:"order"`
+	Enabled bool              `json:"enabled"`
+	Tags    []string          `json:"tags"`
+	Labels  map[string]string `json:"labels"`
+	Nested  *yamlTestDocument `json:"nested,omitempty"`
+}
+
+// laughs nests aliases, so every level doubles the expanded nodes
+func laughs(levels int) string {
+	var b strings.Builder
+	b.WriteString("l0: &l0 [a, a]\n")
+	for i := 1; i <= levels; i++ {
+		fmt.Fprintf(&b, "l%[1]d: &l%[1]d [*l%[2]d, *l%[2]d]\n", i, i-1)
+	}
+	return b.String()
+}
+
+func TestYAMLToJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    string
+		wantErr error
+	}{
+		{name: "empty", yaml: "", want: "null"},
+		{name: "typed scalars", yaml: "version: 1.0\norder: 2\nenabled: true\ntags: [1.0, x]", want: `{"enabled":true,"order":2,"tags":["1.0","x"],"version":"1.0"}`},
+		{name: "unknown keys", yaml: "other: 1.0", want: `{"other":1}`},
+		{name: "map values", yaml: "labels: {a: 1.10}", want: `{"labels":{"a":"1.10"}}`},
+		{name: "null", yaml: "version: null", want: `{"version":null}`},
+		{name: "timestamp", yaml: "other: 2024-01-01", want: `{"other":"2024-01-01"}`},
+		{name: "alias", yaml: "nested: &n {version: 1.0}\nlabels: {a: b}\ntags: [x]\nother: *n", want: `{"labels":{"a":"b"},"nested":{"version":"1.0"},"other":{"version":1},"tags":["x"]}`},
+		{name: "merge", yaml: ".base: &base {version: 1.0, order: 1}\nnested: {<<: *base, order: 2}", want: `{".base":{"order":1,"version":1},"nested":{"order":2,"version":"1.0"}}`},
+		{name: "merge list", yaml: ".a: &a {order: 1}\n.b: &b {version: x, order: 3}\nnested: {<<: [*a, *b]}", want: `{".a":{"order":1},".b":{"order":3,"version":"x"},"nested":{"order":1,"version":"x"}}`},
+		{name: "merge of scalar", yaml: ".a: &a 1\nnested: {<<: *a}", wantErr: errors.New("only mappings can be merged")},
+		{name: "mapping key", yaml: "? [a]\n: b", wantErr: errors.New("key has to be a string")},
+		{name: "multiple documents", yaml: "version: 1\n---\nversion: 2", wantErr: errors.New("only one document is allowed")},
+		{name: "invalid second document", yaml: "version: 1\n---\n[", wantErr: errors.New("yaml")},
+		{name: "billion laughs", yaml: laughs(20), wantErr: errYAMLTooLarge},
+		{name: "few laughs", yaml: laughs(3), want: `{"l0":["a","a"],"l1":[["a","a"],["a","a"]],"l2":[[["a","a"],["a","a"]],[["a","a"],["a","a"]]],"l3":[[[["a","a"],["a","a"]],[["a","a"],["a","a"]]],[[["a","a"],["a","a"]],[["a","a"],["a","a"]]]]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := yamlToJSON([]byte(tt.yaml), reflect.TypeOf(&yamlTestDocument{}))
+			if tt.wantErr != nil {
+				if err == nil || (!errors.Is(err, tt.wantErr) && !strings.Contains(err.Error(), tt.wantErr.Error())) {
+					t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(got) != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestConfigurationID(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "pid.json", want: "pid"},
+		{name: "pid.yaml", want: "pid"},
+		{name: "pid.yml", want: "pid"},
+		{name: "org.iso.18013.5.1.mDL.json", want: "org.iso.18013.5.1.mDL"},
+		{name: "pid", want: "pid"},
+		{name: "pid.txt", want: "pid.txt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := configurationID(tt.name); got != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
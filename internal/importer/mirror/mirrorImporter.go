@@ -0,0 +1,362 @@
+package mirror
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-library/model/credential"
+	"github.com/eclipse-xfsc/oid4-vci-vp-library/model/oauth"
+
+	ctxPkg "github.com/eclipse-xfsc/microservice-core-go/pkg/ctx"
+	logPkg "github.com/eclipse-xfsc/microservice-core-go/pkg/logr"
+	serverPkg "github.com/eclipse-xfsc/microservice-core-go/pkg/server"
+	"github.com/madflojo/tasks"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/config"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/common"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/database"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/importer"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/service"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/types"
+)
+
+const (
+	wellKnownPath = "/.well-known/openid-credential-issuer"
+	// maxMetadataSize limits the size of the upstream response
+	maxMetadataSize = 1 << 20
+)
+
+// Importer mirrors the credential issuer metadata of upstream issuers. The metadata is stored in the
+// database, so the last valid copy is served while an upstream is not available.
+type Importer struct {
+	svc           service.IssuerService
+	asSvc         service.AuthorizationServerService
+	vSvc          service.VerifierService
+	aSvc          service.AssetService
+	conf          config.MirrorConfig
+	client        *http.Client
+	log           logPkg.Logger
+	taskScheduler *tasks.Scheduler
+
+	mu     sync.RWMutex
+	status map[string]TenantStatus
+}
+
+// TenantStatus of the mirrored upstream of a tenant as exposed in the health output
+type TenantStatus struct {
+	Upstream    string     `json:"upstream"`
+	LastRun     *time.Time `json:"last_run,omitempty"`
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+}
+
+var _ importer.Importer = &Importer{}
+
+func NewImporter(
+	svc service.IssuerService,
+	asSvc service.AuthorizationServerService,
+	vSvc service.VerifierService,
+	aSvc service.AssetService,
+	conf config.MirrorConfig,
+	logger logPkg.Logger,
+) *Importer {
+	status := make(map[string]TenantStatus, len(conf.Upstreams))
+	for tenantID, upstream := range conf.Upstreams {
+		status[tenantID] = TenantStatus{Upstream: upstream}
+	}
+
+	return &Importer{
+		svc:           svc,
+		asSvc:         asSvc,
+		vSvc:          vSvc,
+		aSvc:          aSvc,
+		conf:          conf,
+		client:        &http.Client{Timeout: conf.Timeout},
+		log:           logger,
+		taskScheduler: tasks.New(),
+		status:        status,
+	}
+}
+
+func (m *Importer) Start(ctx context.Context, _ *serverPkg.Server, env *common.Environment) error {
+	ctx = ctxPkg.WithLogger(ctx, m.log)
+
+	env.AddHealthDetail("mirror", func() any {
+		return m.Status()
+	})
+
+	_, err := m.taskScheduler.Add(&tasks.Task{
+		TaskContext:       tasks.TaskContext{Context: ctx},
+		Interval:          m.conf.Interval,
+		RunSingleInstance: true,
+		FuncWithTaskContext: func(tc tasks.TaskContext) error {
+			m.mirror(tc.Context)
+			return nil
+		},
+	})
+	if err != nil {
+		m.log.Error(err, "failed to create scheduler for mirror importer")
+		return err
+	}
+
+	// the scheduler runs the first time after the interval
+	go m.mirror(ctx)
+
+	return nil
+}
+
+func (m *Importer) Stop() error {
+	m.taskScheduler.Stop()
+	return nil
+}
+
+// GotErrors is always false, as the last valid copy of an upstream is served while it fails. Failures are
+// part of the health details.
+func (m *Importer) GotErrors() bool {
+	return false
+}
+
+func (m *Importer) Status() map[string]TenantStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	status := make(map[string]TenantStatus, len(m.status))
+	for tenantID, s := range m.status {
+		status[tenantID] = s
+	}
+
+	return status
+}
+
+func (m *Importer) GetCredentialIssuerMetadata(ctx context.Context, tenantID string) (*credential.IssuerMetadata, error) {
+	metadata, err := m.svc.GetIssuer(ctx, tenantID, false)
+	return metadata, translateError(err)
+}
+
+func (m *Importer) GetCredentialConfigurations(ctx context.Context, tenantID string) (map[string]credential.CredentialConfiguration, error) {
+	configurations, err := m.svc.ListConfigurations(ctx, tenantID, false)
+	return configurations, translateError(err)
+}
+
+func (m *Importer) GetCredentialIssuerLastModified(ctx context.Context, tenantID string) (time.Time, error) {
+	lastModified, err := m.svc.GetLastModified(ctx, tenantID)
+	if err != nil {
+		return time.Time{}, translateError(err)
+	}
+
+	// the metadata links the assets by their content
+	assetsModified, err := m.aSvc.GetAssetsLastModified(ctx, tenantID)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if assetsModified.After(lastModified) {
+		lastModified = assetsModified
+	}
+
+	return lastModified, nil
+}
+
+func (m *Importer) GetAuthorizationServerMetadata(ctx context.Context, tenantID string) (*types.AuthorizationServerMetadata, error) {
+	metadata, err := m.asSvc.GetAuthorizationServer(ctx, tenantID)
+	return metadata, translateError(err)
+}
+
+func (m *Importer) GetOpenIDConfiguration(ctx context.Context, tenantID string) (*oauth.OpenIdConfiguration, error) {
+	configuration, err := m.svc.GetOpenIDConfiguration(ctx, tenantID)
+	return configuration, translateError(err)
+}
+
+func (m *Importer) GetVerifierMetadata(ctx context.Context, tenantID string) (*types.VerifierMetadata, error) {
+	metadata, err := m.vSvc.GetVerifier(ctx, tenantID)
+	return metadata, translateError(err)
+}
+
+func (m *Importer) GetJwtVcIssuerMetadata(ctx context.Context, tenantID string) (*types.JwtVcIssuerMetadata, error) {
+	metadata, err := m.svc.GetJwtVcIssuer(ctx, tenantID)
+	return metadata, translateError(err)
+}
+
+func (m *Importer) ListAssets(ctx context.Context, tenantID string) (map[string]types.Asset, error) {
+	assets, err := m.aSvc.ListAssets(ctx, tenantID)
+	return assets, translateError(err)
+}
+
+func (m *Importer) GetAsset(ctx context.Context, tenantID, name string) (*types.Asset, error) {
+	asset, err := m.aSvc.GetAsset(ctx, tenantID, name)
+	return asset, translateError(err)
+}
+
+// translateError maps store errors to the errors defined by the importer package
+func translateError(err error) error {
+	if errors.Is(err, database.ErrNotFound) {
+		return importer.ErrNotFound
+	}
+
+	return err
+}
+
+// mirror fetches the metadata of all upstreams. A failing upstream keeps its stored metadata.
+func (m *Importer) mirror(ctx context.Context) {
+	for tenantID, upstream := range m.conf.Upstreams {
+		now := time.Now()
+
+		err := m.mirrorTenant(ctx, tenantID, upstream)
+		if err != nil {
+			m.log.Error(err, "failed to mirror upstream issuer", "tenant", tenantID, "upstream", upstream)
+		}
+
+		m.mu.Lock()
+		status := m.status[tenantID]
+		status.LastRun = &now
+		status.LastError = ""
+		if err != nil {
+			status.LastError = err.Error()
+		} else {
+			status.LastSuccess = &now
+		}
+		m.status[tenantID] = status
+		m.mu.Unlock()
+	}
+}
+
+func (m *Importer) mirrorTenant(ctx context.Context, tenantID, upstream string) error {
+	since := time.Now()
+
+	metadata, err := m.fetch(ctx, upstream)
+	if err != nil {
+		return err
+	}
+
+	if err := m.svc.UpsertIssuer(ctx, tenantID, *metadata); err != nil {
+		return err
+	}
+
+	// configurations which were removed upstream are removed as well
+	result, err := m.svc.Reconcile(ctx, tenantID, since, true)
+	if err != nil {
+		return err
+	}
+
+	if len(result.MissingConfigurations) > 0 {
+		m.log.Info("removed credential configurations which are no longer offered upstream",
+			"tenant", tenantID,
+			"configurations", result.MissingConfigurations,
+		)
+	}
+
+	return nil
+}
+
+// fetch loads and validates the metadata of the upstream credential issuer and rewrites the hosts of its
+// endpoints
+func (m *Importer) fetch(ctx context.Context, upstream string) (*credential.IssuerMetadata, error) {
+	metadataURL, err := wellKnownURL(upstream)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, metadataURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d from %s", resp.StatusCode, metadataURL)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxMetadataSize))
+	if err != nil {
+		return nil, err
+	}
+
+	var metadata credential.IssuerMetadata
+	if err := json.Unmarshal(body, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to decode metadata of %s: %w", metadataURL, err)
+	}
+
+	if err := types.ValidateIssuerMetadata(&metadata); err != nil {
+		return nil, fmt.Errorf("invalid metadata of %s: %w", metadataURL, err)
+	}
+
+	if strings.TrimSuffix(metadata.CredentialIssuer, "/") != strings.TrimSuffix(upstream, "/") {
+		return nil, fmt.Errorf("credential_issuer %s does not match upstream %s", metadata.CredentialIssuer, upstream)
+	}
+
+	if m.rewrite(&metadata) {
+		// the signature of the upstream does not cover the rewritten endpoints
+		metadata.SignedMetadata = nil
+	}
+
+	return &metadata, nil
+}
+
+// rewrite replaces the hosts of the endpoints as configured and reports whether anything was changed
+func (m *Importer) rewrite(metadata *credential.IssuerMetadata) bool {
+	if len(m.conf.RewriteHosts) == 0 {
+		return false
+	}
+
+	changed := false
+	replace := func(value *string) {
+		if value == nil {
+			return
+		}
+
+		u, err := url.Parse(*value)
+		if err != nil {
+			return
+		}
+
+		host, ok := m.conf.RewriteHosts[u.Host]
+		if !ok {
+			return
+		}
+
+		u.Host = host
+		*value = u.String()
+		changed = true
+	}
+
+	replace(&metadata.CredentialIssuer)
+	replace(&metadata.CredentialEndpoint)
+	replace(metadata.BatchCredentialEndpoint)
+	replace(metadata.DeferredCredentialEndpoint)
+	replace(metadata.NotificationEndpoint)
+
+	for i := range metadata.AuthorizationServers {
+		replace(&metadata.AuthorizationServers[i])
+	}
+
+	return changed
+}
+
+// wellKnownURL inserts the well-known path between the host and the path of the credential issuer identifier,
+// which has no query or fragment (OID4VCI, section 11.2.1)
+func wellKnownURL(issuer string) (string, error) {
+	u, err := url.Parse(issuer)
+	if err != nil || !u.IsAbs() || u.Host == "" || u.RawQuery != "" || u.Fragment != "" {
+		return "", fmt.Errorf("invalid upstream %q", issuer)
+	}
+
+	// escaped slashes of the identifier stay escaped
+	u.RawPath = wellKnownPath + strings.TrimSuffix(u.EscapedPath(), "/")
+	u.Path = wellKnownPath + strings.TrimSuffix(u.Path, "/")
+
+	return u.String(), nil
+}
@@ -0,0 +1,282 @@
+package mirror
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	ctxPkg "github.com/eclipse-xfsc/microservice-core-go/pkg/ctx"
+	logPkg "github.com/eclipse-xfsc/microservice-core-go/pkg/logr"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/config"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/database"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/database/issuers"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/service"
+)
+
+func TestWellKnownURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		issuer  string
+		want    string
+		wantErr bool
+	}{
+		{name: "host", issuer: "https://issuer.example", want: "https://issuer.example/.well-known/openid-credential-issuer"},
+		{name: "trailing slash", issuer: "https://issuer.example/", want: "https://issuer.example/.well-known/openid-credential-issuer"},
+		{name: "port", issuer: "https://issuer.example:8443", want: "https://issuer.example:8443/.well-known/openid-credential-issuer"},
+		{name: "path", issuer: "https://issuer.example/tenant", want: "https://issuer.example/.well-known/openid-credential-issuer/tenant"},
+		{name: "path with trailing slash", issuer: "https://issuer.example/v1/tenant/", want: "https://issuer.example/.well-known/openid-credential-issuer/v1/tenant"},
+		{name: "escaped path", issuer: "https://issuer.example/a%2Fb", want: "https://issuer.example/.well-known/openid-credential-issuer/a%2Fb"},
+		{name: "relative", issuer: "/tenant", wantErr: true},
+		{name: "no host", issuer: "https:///tenant", wantErr: true},
+		{name: "query", issuer: "https://issuer.example/tenant?a=b", wantErr: true},
+		{name: "fragment", issuer: "https://issuer.example/tenant#a", wantErr: true},
+		{name: "invalid", issuer: "https://issuer example", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := wellKnownURL(tt.issuer)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+
+			if got != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+// memoryStore keeps the issuer records of the mirrored tenants, methods the tests do not use panic
+type memoryStore struct {
+	issuers.Store
+	issuers        map[string]issuers.Issuer
+	configurations map[string]map[string]issuers.CredentialsSupported
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		issuers:        make(map[string]issuers.Issuer),
+		configurations: make(map[string]map[string]issuers.CredentialsSupported),
+	}
+}
+
+func (m *memoryStore) GetIssuerRecord(ctx context.Context, tenantID string) (*issuers.Issuer, error) {
+	issuer, ok := m.issuers[tenantID]
+	if !ok {
+		return nil, database.ErrNotFound
+	}
+
+	issuer.CredentialsSupported, _ = m.GetConfigurationsRecord(ctx, tenantID)
+	return &issuer, nil
+}
+
+func (m *memoryStore) GetConfigurationsRecord(_ context.Context, tenantID string) ([]issuers.CredentialsSupported, error) {
+	if len(m.configurations[tenantID]) == 0 {
+		return nil, database.ErrNotFound
+	}
+
+	return slices.Collect(maps.Values(m.configurations[tenantID])), nil
+}
+
+func (m *memoryStore) InsertIssuerRecord(ctx context.Context, issuer issuers.Issuer) error {
+	m.issuers[issuer.TenantID] = issuer
+	return m.UpdateConfigurationsSupported(ctx, issuer.TenantID, issuer.CredentialsSupported)
+}
+
+func (m *memoryStore) ReplaceIssuerRecord(ctx context.Context, issuer issuers.Issuer) error {
+	if _, ok := m.issuers[issuer.TenantID]; !ok {
+		return database.ErrNotFound
+	}
+
+	return m.InsertIssuerRecord(ctx, issuer)
+}
+
+func (m *memoryStore) UpdateConfigurationsSupported(_ context.Context, tenantID string, update []issuers.CredentialsSupported) error {
+	if m.configurations[tenantID] == nil {
+		m.configurations[tenantID] = make(map[string]issuers.CredentialsSupported)
+	}
+
+	for _, c := range update {
+		m.configurations[tenantID][c.CredentialConfigurationID] = c
+	}
+	return nil
+}
+
+func (m *memoryStore) DeleteConfigurationRecord(_ context.Context, tenantID, configurationID string) error {
+	if _, ok := m.configurations[tenantID][configurationID]; !ok {
+		return database.ErrNotFound
+	}
+
+	delete(m.configurations[tenantID], configurationID)
+	return nil
+}
+
+// upstreamMetadata returns the metadata of an upstream served at the URL
+func upstreamMetadata(upstream string) map[string]interface{} {
+	return map[string]interface{}{
+		"credential_issuer":     upstream,
+		"credential_endpoint":   upstream + "/credential",
+		"authorization_servers": []string{upstream + "/auth"},
+		"signed_metadata":       "eyJhbGciOiJFUzI1NiJ9.e30.c2ln",
+		"credential_configurations_supported": map[string]interface{}{
+			"pid": map[string]interface{}{"format": "vc+sd-jwt", "vct": "pid"},
+		},
+	}
+}
+
+func TestFetch(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       func(upstream string) interface{}
+		rewrite    bool
+		wantErr    string
+		wantSigned bool
+	}{
+		{
+			name:       "valid",
+			body:       func(upstream string) interface{} { return upstreamMetadata(upstream) },
+			wantSigned: true,
+		},
+		{
+			name:    "host rewritten",
+			body:    func(upstream string) interface{} { return upstreamMetadata(upstream) },
+			rewrite: true,
+		},
+		{
+			name: "credential_issuer mismatch",
+			body: func(string) interface{} {
+				return upstreamMetadata("https://other.example")
+			},
+			wantErr: "does not match upstream",
+		},
+		{
+			name: "invalid metadata",
+			body: func(upstream string) interface{} {
+				metadata := upstreamMetadata(upstream)
+				delete(metadata, "credential_endpoint")
+				return metadata
+			},
+			wantErr: "invalid metadata",
+		},
+		{
+			name:    "invalid json",
+			body:    func(string) interface{} { return "metadata" },
+			wantErr: "failed to decode",
+		},
+		{
+			name:    "upstream failure",
+			status:  http.StatusServiceUnavailable,
+			body:    func(upstream string) interface{} { return upstreamMetadata(upstream) },
+			wantErr: "unexpected status 503",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var upstream string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != wellKnownPath {
+					http.NotFound(w, r)
+					return
+				}
+
+				if tt.status != 0 {
+					w.WriteHeader(tt.status)
+				}
+				_ = json.NewEncoder(w).Encode(tt.body(upstream))
+			}))
+			defer server.Close()
+			upstream = server.URL
+
+			conf := config.MirrorConfig{Timeout: time.Second}
+			if tt.rewrite {
+				conf.RewriteHosts = config.KeyValues{strings.TrimPrefix(upstream, "http://"): "wellknown.example"}
+			}
+
+			m := NewImporter(service.IssuerService{}, service.AuthorizationServerService{}, service.VerifierService{},
+				service.AssetService{}, conf, logPkg.Logger{})
+
+			metadata, err := m.fetch(context.Background(), upstream)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if (metadata.SignedMetadata != nil) != tt.wantSigned {
+				t.Fatalf("expected signed_metadata %v, got %v", tt.wantSigned, metadata.SignedMetadata)
+			}
+
+			host := strings.TrimPrefix(upstream, "http://")
+			if tt.rewrite {
+				host = "wellknown.example"
+			}
+			for _, endpoint := range []string{metadata.CredentialIssuer, metadata.CredentialEndpoint, metadata.AuthorizationServers[0]} {
+				if !strings.HasPrefix(endpoint, "http://"+host) {
+					t.Fatalf("expected host %s, got %s", host, endpoint)
+				}
+			}
+		})
+	}
+}
+
+func TestMirrorKeepsLastValidCopy(t *testing.T) {
+	var failing atomic.Bool
+	var upstream string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(upstreamMetadata(upstream))
+	}))
+	defer server.Close()
+	upstream = server.URL
+
+	logger, err := logPkg.New("error", false, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	svc := service.NewIssuerService(newMemoryStore(), nil).WithSource(issuers.SourceMirror)
+	m := NewImporter(svc, service.AuthorizationServerService{}, service.VerifierService{}, service.AssetService{},
+		config.MirrorConfig{Upstreams: config.KeyValues{"tenant": upstream}, Timeout: time.Second}, *logger)
+
+	ctx := ctxPkg.WithLogger(context.Background(), *logger)
+
+	m.mirror(ctx)
+	failing.Store(true)
+	m.mirror(ctx)
+
+	metadata, err := m.GetCredentialIssuerMetadata(ctx, "tenant")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.CredentialIssuer != upstream {
+		t.Fatalf("expected credential_issuer %s, got %s", upstream, metadata.CredentialIssuer)
+	}
+	if _, ok := metadata.CredentialConfigurationsSupported["pid"]; !ok {
+		t.Fatalf("expected the mirrored credential configuration, got %v", metadata.CredentialConfigurationsSupported)
+	}
+
+	status := m.Status()["tenant"]
+	if status.LastSuccess == nil || status.LastError == "" || !status.LastRun.After(*status.LastSuccess) {
+		t.Fatalf("expected the failed run after the last success, got %+v", status)
+	}
+}
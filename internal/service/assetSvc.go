@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	ctxPkg "github.com/eclipse-xfsc/microservice-core-go/pkg/ctx"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/database"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/database/assets"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/types"
+)
+
+type AssetService struct {
+	store assets.Store
+}
+
+func NewAssetService(store assets.Store) AssetService {
+	return AssetService{store: store}
+}
+
+func (s AssetService) GetAsset(ctx context.Context, tenantID, name string) (*types.Asset, error) {
+	asset, err := s.store.GetAssetRecord(ctx, tenantID, name)
+	if err != nil {
+		if !errors.Is(err, database.ErrNotFound) {
+			ctxPkg.GetLogger(ctx).Error(err, "failed to get asset record")
+		}
+		return nil, err
+	}
+
+	return asset, nil
+}
+
+// ListAssets returns the assets of the tenant keyed by name, without their content
+func (s AssetService) ListAssets(ctx context.Context, tenantID string) (map[string]types.Asset, error) {
+	records, err := s.store.ListAssetRecords(ctx, tenantID)
+	if err != nil {
+		ctxPkg.GetLogger(ctx).Error(err, "failed to list asset records")
+		return nil, err
+	}
+
+	out := make(map[string]types.Asset, len(records))
+	for _, asset := range records {
+		out[asset.Name] = asset
+	}
+
+	return out, nil
+}
+
+// GetAssetsLastModified returns the latest upload or deletion of an asset of the tenant, which is zero without
+// assets
+func (s AssetService) GetAssetsLastModified(ctx context.Context, tenantID string) (time.Time, error) {
+	lastModified, err := s.store.GetAssetsLastModified(ctx, tenantID)
+	if err != nil {
+		ctxPkg.GetLogger(ctx).Error(err, "failed to get modification time of assets")
+		return time.Time{}, err
+	}
+
+	return lastModified, nil
+}
+
+// UpsertAsset stores the content under the name or, if the tenant already has an asset of that name, replaces it
+func (s AssetService) UpsertAsset(ctx context.Context, tenantID, name string, content []byte) (*types.Asset, error) {
+	asset := types.NewAsset(name, content, time.Now())
+
+	if err := s.store.UpsertAssetRecord(ctx, tenantID, asset); err != nil {
+		ctxPkg.GetLogger(ctx).Error(err, "failed to upsert asset")
+		return nil, err
+	}
+
+	return &asset, nil
+}
+
+// DeleteAsset removes the asset of the tenant
+func (s AssetService) DeleteAsset(ctx context.Context, tenantID, name string) error {
+	if err := s.store.DeleteAssetRecord(ctx, tenantID, name); err != nil {
+		if !errors.Is(err, database.ErrNotFound) {
+			ctxPkg.GetLogger(ctx).Error(err, "failed to delete asset")
+		}
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	ctxPkg "github.com/eclipse-xfsc/microservice-core-go/pkg/ctx"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/database"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/database/authservers"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/types"
+)
+
+type AuthorizationServerService struct {
+	store authservers.Store
+}
+
+func NewAuthorizationServerService(store authservers.Store) AuthorizationServerService {
+	return AuthorizationServerService{store: store}
+}
+
+func (s AuthorizationServerService) GetAuthorizationServer(ctx context.Context, tenantID string) (*types.AuthorizationServerMetadata, error) {
+	record, err := s.store.GetAuthorizationServerRecord(ctx, tenantID)
+	if err != nil {
+		if !errors.Is(err, database.ErrNotFound) {
+			ctxPkg.GetLogger(ctx).Error(err, "failed to get authorization server record")
+		}
+		return nil, err
+	}
+
+	return &record.Metadata, nil
+}
+
+// UpsertAuthorizationServer will store the given authorization server metadata or, if it already exists,
+// replace the existing record
+func (s AuthorizationServerService) UpsertAuthorizationServer(ctx context.Context, tenantID string, metadata types.AuthorizationServerMetadata) error {
+	if err := metadata.Validate(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	record := authservers.AuthorizationServer{
+		TenantID:  tenantID,
+		Metadata:  metadata,
+		FirstSeen: now,
+		LastSeen:  now,
+	}
+
+	if err := s.store.UpsertAuthorizationServerRecord(ctx, record); err != nil {
+		ctxPkg.GetLogger(ctx).Error(err, "failed to upsert authorization server")
+		return err
+	}
+
+	return nil
+}
+
+// DeleteAuthorizationServer removes the authorization server metadata of the tenant
+func (s AuthorizationServerService) DeleteAuthorizationServer(ctx context.Context, tenantID string) error {
+	if err := s.store.DeleteAuthorizationServerRecord(ctx, tenantID); err != nil {
+		if !errors.Is(err, database.ErrNotFound) {
+			ctxPkg.GetLogger(ctx).Error(err, "failed to delete authorization server")
+		}
+		return err
+	}
+
+	return nil
+}
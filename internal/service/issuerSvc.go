@@ -3,23 +3,71 @@ package service
 import (
 	"context"
 	"errors"
+	"slices"
 	"time"
 
 	"github.com/eclipse-xfsc/oid4-vci-vp-library/model/credential"
+	"github.com/eclipse-xfsc/oid4-vci-vp-library/model/oauth"
 
 	ctxPkg "github.com/eclipse-xfsc/microservice-core-go/pkg/ctx"
 
 	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/database"
 
 	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/database/issuers"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/signer"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/types"
 )
 
+var (
+	ErrAlreadyExists             = errors.New("already exists")
+	ErrCredentialIssuerImmutable = errors.New("credential_issuer can not be changed")
+	ErrForeignRecord             = errors.New("record is managed by another source")
+)
+
+// Reconciliation lists the records of a tenant which were not registered again after a solicitation
+type Reconciliation struct {
+	TenantID              string   `json:"tenant_id"`
+	IssuerMissing         bool     `json:"issuer_missing,omitempty"`
+	MissingConfigurations []string `json:"missing_configurations,omitempty"`
+	Pruned                bool     `json:"pruned,omitempty"`
+}
+
 type IssuerService struct {
-	store issuers.Store
+	store  issuers.Store
+	signer *signer.Signer
+	source string
+}
+
+// NewIssuerService creates the service. If a signer is given, the service signs the metadata of each tenant
+// itself and ignores signed_metadata provided by publishers. Records are written as broadcast records, see
+// WithSource.
+func NewIssuerService(store issuers.Store, signer *signer.Signer) IssuerService {
+	return IssuerService{store: store, signer: signer, source: issuers.SourceBroadcast}
+}
+
+// WithSource returns a service which writes records of the given source (issuers.SourceAdmin, ...). Expiration
+// and pruning of the returned service only remove records of this source.
+func (s IssuerService) WithSource(source string) IssuerService {
+	s.source = source
+	return s
 }
 
-func NewIssuerService(store issuers.Store) IssuerService {
-	return IssuerService{store: store}
+// ownedSources returns the sources of the records the service may replace or remove. The admin API manages the
+// records of every source (nil), records stored before sources were tracked were registered by broadcasts.
+func (s IssuerService) ownedSources() []string {
+	switch s.source {
+	case issuers.SourceAdmin:
+		return nil
+	case issuers.SourceBroadcast:
+		return []string{issuers.SourceBroadcast, ""}
+	default:
+		return []string{s.source}
+	}
+}
+
+func (s IssuerService) owns(source string) bool {
+	owned := s.ownedSources()
+	return owned == nil || slices.Contains(owned, source)
 }
 
 func (s IssuerService) GetIssuer(ctx context.Context, tenantID string, withInternal bool) (*credential.IssuerMetadata, error) {
@@ -30,31 +78,42 @@ func (s IssuerService) GetIssuer(ctx context.Context, tenantID string, withInter
 		return nil, err
 	}
 
-	cs := make(map[string]credential.CredentialConfiguration)
-	for _, supported := range issuer.CredentialsSupported {
-		id := supported.CredentialConfigurationID
+	return issuerMetadata(issuer, withInternal), nil
+}
 
-		cs[id] = credential.CredentialConfiguration{
-			Format:                               supported.Format,
-			Scope:                                supported.Scope,
-			CryptographicBindingMethodsSupported: supported.CryptographicBindingMethodsSupported,
-			CredentialSigningAlgValuesSupported:  supported.CryptographicSigningAlgValuesSupported,
-			ProofTypesSupported:                  supported.ProofTypesSupported,
-			CredentialDefinition:                 supported.CredentialDefinition,
-			Display:                              supported.Display,
-			Vct:                                  supported.Vct,
-			Claims:                               supported.Claims,
-			Order:                                supported.Order,
-			Schema:                               supported.Schema,
-			Subject:                              supported.Subject,
-		}
+// GetLastModified returns the time the issuer metadata of the tenant was last registered
+func (s IssuerService) GetLastModified(ctx context.Context, tenantID string) (time.Time, error) {
+	return s.store.GetLastModified(ctx, tenantID)
+}
 
-		if withInternal {
-			config := cs[id]
-			config.Schema = supported.Schema
-			config.Subject = supported.Subject
-			cs[id] = config
-		}
+func credentialConfiguration(supported issuers.CredentialsSupported, withInternal bool) credential.CredentialConfiguration {
+	config := credential.CredentialConfiguration{
+		Format:                               supported.Format,
+		Scope:                                supported.Scope,
+		CryptographicBindingMethodsSupported: supported.CryptographicBindingMethodsSupported,
+		CredentialSigningAlgValuesSupported:  supported.CryptographicSigningAlgValuesSupported,
+		ProofTypesSupported:                  supported.ProofTypesSupported,
+		CredentialDefinition:                 supported.CredentialDefinition,
+		Display:                              supported.Display,
+		Vct:                                  supported.Vct,
+		Claims:                               supported.Claims,
+		Order:                                supported.Order,
+		Schema:                               supported.Schema,
+		Subject:                              supported.Subject,
+	}
+
+	if withInternal {
+		config.Schema = supported.Schema
+		config.Subject = supported.Subject
+	}
+
+	return config
+}
+
+func issuerMetadata(issuer *issuers.Issuer, withInternal bool) *credential.IssuerMetadata {
+	cs := make(map[string]credential.CredentialConfiguration)
+	for _, supported := range issuer.CredentialsSupported {
+		cs[supported.CredentialConfigurationID] = credentialConfiguration(supported, withInternal)
 	}
 
 	iss := &credential.IssuerMetadata{
@@ -68,7 +127,6 @@ func (s IssuerService) GetIssuer(ctx context.Context, tenantID string, withInter
 		CredentialIdentifiersSupported:    issuer.CredentialIdentifiersSupported,
 		SignedMetadata:                    issuer.SignedMetadata,
 		CredentialConfigurationsSupported: cs,
-		CredentialResponseEncryption:      credential.CredentialRespEnc(*issuer.CredentialResponseEncryption),
 	}
 
 	if issuer.CredentialResponseEncryption != nil {
@@ -79,10 +137,12 @@ func (s IssuerService) GetIssuer(ctx context.Context, tenantID string, withInter
 		}
 	}
 
-	return iss, nil
+	return iss
 }
 
-// UpsertIssuer will store the given issuer or, if it already exists, update the existing record
+// UpsertIssuer will store the given issuer or, if it already exists, replace the existing record. Fields
+// missing in the given issuer are cleared; credential configurations missing in it are kept. An issuer of
+// another source is not replaced (ErrForeignRecord), credential configurations of other sources are kept.
 func (s IssuerService) UpsertIssuer(ctx context.Context, tenantID string, issuer credential.IssuerMetadata) error {
 	log := ctxPkg.GetLogger(ctx)
 
@@ -90,9 +150,34 @@ func (s IssuerService) UpsertIssuer(ctx context.Context, tenantID string, issuer
 	if err != nil && !errors.Is(err, database.ErrNotFound) {
 		return err
 	}
+
+	if storedIssuer != nil && !s.owns(storedIssuer.Source) {
+		return ErrForeignRecord
+	}
+
+	storedConfigurations, err := s.store.GetConfigurationsRecord(ctx, tenantID)
+	if err != nil && !errors.Is(err, database.ErrNotFound) {
+		return err
+	}
+
+	firstSeen := make(map[string]time.Time, len(storedConfigurations))
+	foreign := make(map[string]bool)
+	for _, c := range storedConfigurations {
+		firstSeen[c.CredentialConfigurationID] = c.FirstSeen
+		foreign[c.CredentialConfigurationID] = !s.owns(c.Source)
+	}
+
+	if s.signer != nil {
+		issuer.SignedMetadata = nil
+	}
+
 	now := time.Now()
 	cs := make([]issuers.CredentialsSupported, 0)
 	for ccid, supported := range issuer.CredentialConfigurationsSupported {
+		if foreign[ccid] {
+			log.Info("keeping credential configuration of another source", "tenant", tenantID, "configuration", ccid)
+			continue
+		}
 
 		sup := issuers.CredentialsSupported{
 			CredentialConfigurationID:              ccid,
@@ -110,91 +195,65 @@ func (s IssuerService) UpsertIssuer(ctx context.Context, tenantID string, issuer
 			Order:                                  supported.Order,
 			LastSeen:                               now,
 			FirstSeen:                              now,
+			Source:                                 s.source,
 		}
-		cs = append(cs, sup)
-	}
 
-	isNew := storedIssuer == nil
-
-	if isNew {
-		storedIssuer = &issuers.Issuer{
-			TenantID:                   tenantID,
-			CredentialIssuer:           issuer.CredentialIssuer,
-			AuthorizationServers:       issuer.AuthorizationServers,
-			CredentialEndpoint:         issuer.CredentialEndpoint,
-			BatchCredentialEndpoint:    issuer.BatchCredentialEndpoint,
-			DeferredCredentialEndpoint: issuer.DeferredCredentialEndpoint,
-			NotificationEndpoint:       issuer.NotificationEndpoint,
-			CredentialResponseEncryption: &issuers.CredentialRespEnc{
-				AlgValuesSupported: issuer.CredentialResponseEncryption.AlgValuesSupported,
-				EncValuesSupported: issuer.CredentialResponseEncryption.EncValuesSupported,
-				EncryptionRequired: issuer.CredentialResponseEncryption.EncryptionRequired,
-			},
-			CredentialsSupported:           cs,
-			LastSeen:                       now,
-			FirstSeen:                      now,
-			Display:                        issuer.Display,
-			SignedMetadata:                 issuer.SignedMetadata,
-			CredentialIdentifiersSupported: issuer.CredentialIdentifiersSupported,
-		}
-
-		if err := s.store.InsertIssuerRecord(ctx, *storedIssuer); err != nil {
-			log.Error(err, "failed to insert new issuer", "prev", errors.Unwrap(err))
-			return err
+		if seen, ok := firstSeen[ccid]; ok {
+			sup.FirstSeen = seen
 		}
 
-		return nil
+		cs = append(cs, sup)
 	}
 
-	update := issuers.IssuerUpdate{
-		AuthorizationServers:           issuer.AuthorizationServers,
-		CredentialEndpoint:             &issuer.CredentialEndpoint,
-		BatchCredentialEndpoint:        issuer.BatchCredentialEndpoint,
-		DeferredCredentialEndpoint:     issuer.DeferredCredentialEndpoint,
-		NotificationEndpoint:           issuer.NotificationEndpoint,
+	record := issuers.Issuer{
+		TenantID:                   tenantID,
+		CredentialIssuer:           issuer.CredentialIssuer,
+		AuthorizationServers:       issuer.AuthorizationServers,
+		CredentialEndpoint:         issuer.CredentialEndpoint,
+		BatchCredentialEndpoint:    issuer.BatchCredentialEndpoint,
+		DeferredCredentialEndpoint: issuer.DeferredCredentialEndpoint,
+		NotificationEndpoint:       issuer.NotificationEndpoint,
+		CredentialResponseEncryption: &issuers.CredentialRespEnc{
+			AlgValuesSupported: issuer.CredentialResponseEncryption.AlgValuesSupported,
+			EncValuesSupported: issuer.CredentialResponseEncryption.EncValuesSupported,
+			EncryptionRequired: issuer.CredentialResponseEncryption.EncryptionRequired,
+		},
+		CredentialsSupported:           cs,
+		LastSeen:                       now,
+		FirstSeen:                      now,
+		Display:                        issuer.Display,
 		SignedMetadata:                 issuer.SignedMetadata,
 		CredentialIdentifiersSupported: issuer.CredentialIdentifiersSupported,
-		Display:                        issuer.Display,
-		LastSeen:                       &now,
-	}
-
-	finalCs := make([]issuers.CredentialsSupported, 0)
-
-	//Update last seen if exist
-	for i, c := range finalCs {
-		for _, x := range cs {
-			if x.CredentialConfigurationID == c.CredentialConfigurationID {
-				finalCs[i].LastSeen = now
-				continue
-			}
-		}
+		Source:                         s.source,
 	}
 
-	for _, x := range cs {
-		found := false
-		for _, c := range finalCs {
-			if x.CredentialConfigurationID == c.CredentialConfigurationID {
-				found = true
-				break
-			}
+	if storedIssuer == nil {
+		if err := s.store.InsertIssuerRecord(ctx, record); err != nil {
+			log.Error(err, "failed to insert new issuer", "prev", errors.Unwrap(err))
+			return err
 		}
 
-		if !found {
-			finalCs = append(finalCs, x)
-		}
+		return s.signMetadata(ctx, tenantID)
 	}
 
-	update.CredentialsSupported = finalCs
+	// the own signature is kept, signMetadata replaces it only if the content changed
+	if s.signer != nil {
+		record.SignedMetadata = storedIssuer.SignedMetadata
+		record.SignedMetadataDigest = storedIssuer.SignedMetadataDigest
+	}
 
-	if err := s.store.UpdateIssuerRecord(ctx, tenantID, issuer.CredentialIssuer, update); err != nil {
-		log.Error(err, "failed to update existing issuer")
+	if err := s.store.ReplaceIssuerRecord(ctx, record); err != nil {
+		if !errors.Is(err, database.ErrNotFound) {
+			log.Error(err, "failed to replace existing issuer")
+		}
 		return err
 	}
 
-	return nil
+	return s.signMetadata(ctx, tenantID)
 }
 
-// UpsertIssuer will store the given issuer or, if it already exists, update the existing record
+// UpsertConfiguration will store the given configuration or, if it already exists, replace the existing record.
+// A configuration of another source is not replaced (ErrForeignRecord).
 func (s IssuerService) UpsertConfiguration(ctx context.Context, tenantID string, configurationId string, configuration credential.CredentialConfiguration) error {
 	log := ctxPkg.GetLogger(ctx)
 
@@ -219,28 +278,370 @@ func (s IssuerService) UpsertConfiguration(ctx context.Context, tenantID string,
 		Vct:                                    configuration.Vct,
 		Claims:                                 configuration.Claims,
 		Order:                                  configuration.Order,
+		FirstSeen:                              now,
 		LastSeen:                               now,
+		Source:                                 s.source,
 	}
-	isNew := true
-	finalCs := make([]issuers.CredentialsSupported, 0)
+
 	for _, c := range storedConfiguration {
-		if c.CredentialConfigurationID == configurationId {
-			isNew = false
+		if c.CredentialConfigurationID != configurationId {
 			continue
 		}
-		finalCs = append(finalCs, c)
-	}
-	// cs := make([]issuers.CredentialsSupported, 0)
 
-	if isNew {
-		sup.FirstSeen = now
+		if !s.owns(c.Source) {
+			return ErrForeignRecord
+		}
+		sup.FirstSeen = c.FirstSeen
 	}
 
-	finalCs = append(finalCs, sup)
-	if err := s.store.UpdateConfigurationsSupported(ctx, tenantID, finalCs); err != nil {
+	// only the given configuration is replaced, the others keep their source and last_seen
+	if err := s.store.UpdateConfigurationsSupported(ctx, tenantID, []issuers.CredentialsSupported{sup}); err != nil {
 		log.Error(err, "failed to update existing issuer")
 		return err
 	}
 
+	return s.signMetadata(ctx, tenantID)
+}
+
+// CreateIssuer stores the issuer of a tenant which has none yet
+func (s IssuerService) CreateIssuer(ctx context.Context, tenantID string, issuer credential.IssuerMetadata) error {
+	_, err := s.store.GetIssuerRecord(ctx, tenantID)
+	if err == nil {
+		return ErrAlreadyExists
+	}
+
+	if !errors.Is(err, database.ErrNotFound) {
+		return err
+	}
+
+	return s.UpsertIssuer(ctx, tenantID, issuer)
+}
+
+// UpdateIssuer replaces the issuer of a tenant. The credential issuer identifies the record and can not be changed.
+func (s IssuerService) UpdateIssuer(ctx context.Context, tenantID string, issuer credential.IssuerMetadata) error {
+	stored, err := s.store.GetIssuerRecord(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	if stored.CredentialIssuer != issuer.CredentialIssuer {
+		return ErrCredentialIssuerImmutable
+	}
+
+	return s.UpsertIssuer(ctx, tenantID, issuer)
+}
+
+// DeleteIssuer removes the issuer of the tenant including its credential configurations. Credential
+// configurations of other sources are kept, an issuer of another source is not removed (ErrForeignRecord).
+func (s IssuerService) DeleteIssuer(ctx context.Context, tenantID string) error {
+	owned, err := s.OwnsIssuer(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	if !owned {
+		return ErrForeignRecord
+	}
+
+	if err := s.store.DeleteIssuerRecord(ctx, tenantID, s.ownedSources()); err != nil {
+		if !errors.Is(err, database.ErrNotFound) {
+			ctxPkg.GetLogger(ctx).Error(err, "failed to delete issuer")
+		}
+		return err
+	}
+
+	return nil
+}
+
+// ListConfigurations returns the credential configurations of the tenant by their id
+func (s IssuerService) ListConfigurations(ctx context.Context, tenantID string, withInternal bool) (map[string]credential.CredentialConfiguration, error) {
+	records, err := s.store.GetConfigurationsRecord(ctx, tenantID)
+	if err != nil && !errors.Is(err, database.ErrNotFound) {
+		return nil, err
+	}
+
+	configurations := make(map[string]credential.CredentialConfiguration, len(records))
+	for _, record := range records {
+		configurations[record.CredentialConfigurationID] = credentialConfiguration(record, withInternal)
+	}
+
+	return configurations, nil
+}
+
+// GetConfiguration returns a single credential configuration of the tenant
+func (s IssuerService) GetConfiguration(ctx context.Context, tenantID, configurationID string, withInternal bool) (*credential.CredentialConfiguration, error) {
+	configurations, err := s.ListConfigurations(ctx, tenantID, withInternal)
+	if err != nil {
+		return nil, err
+	}
+
+	configuration, ok := configurations[configurationID]
+	if !ok {
+		return nil, database.ErrNotFound
+	}
+
+	return &configuration, nil
+}
+
+// CreateConfiguration stores a credential configuration which does not exist yet
+func (s IssuerService) CreateConfiguration(ctx context.Context, tenantID, configurationID string, configuration credential.CredentialConfiguration) error {
+	_, err := s.GetConfiguration(ctx, tenantID, configurationID, false)
+	if err == nil {
+		return ErrAlreadyExists
+	}
+
+	if !errors.Is(err, database.ErrNotFound) {
+		return err
+	}
+
+	return s.UpsertConfiguration(ctx, tenantID, configurationID, configuration)
+}
+
+// UpdateConfiguration replaces an existing credential configuration
+func (s IssuerService) UpdateConfiguration(ctx context.Context, tenantID, configurationID string, configuration credential.CredentialConfiguration) error {
+	if _, err := s.GetConfiguration(ctx, tenantID, configurationID, false); err != nil {
+		return err
+	}
+
+	return s.UpsertConfiguration(ctx, tenantID, configurationID, configuration)
+}
+
+// DeleteConfiguration removes a credential configuration of the tenant. A configuration of another source
+// is not removed (ErrForeignRecord).
+func (s IssuerService) DeleteConfiguration(ctx context.Context, tenantID, configurationID string) error {
+	configurations, err := s.store.GetConfigurationsRecord(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	index := slices.IndexFunc(configurations, func(c issuers.CredentialsSupported) bool {
+		return c.CredentialConfigurationID == configurationID
+	})
+	if index < 0 {
+		return database.ErrNotFound
+	}
+
+	if !s.owns(configurations[index].Source) {
+		return ErrForeignRecord
+	}
+
+	if err := s.store.DeleteConfigurationRecord(ctx, tenantID, configurationID); err != nil {
+		if !errors.Is(err, database.ErrNotFound) {
+			ctxPkg.GetLogger(ctx).Error(err, "failed to delete credential configuration")
+		}
+		return err
+	}
+
+	return s.signMetadata(ctx, tenantID)
+}
+
+// PruneExpiredConfigurations removes the credential configurations of the source of the service which were not
+// registered since before and re-signs the metadata of the affected tenants
+func (s IssuerService) PruneExpiredConfigurations(ctx context.Context, before time.Time) ([]issuers.CredentialsSupported, error) {
+	log := ctxPkg.GetLogger(ctx)
+
+	pruned, err := s.store.DeleteExpiredConfigurations(ctx, s.source, before)
+	if err != nil {
+		log.Error(err, "failed to delete expired credential configurations")
+		return nil, err
+	}
+
+	signed := make(map[string]bool)
+	for _, cs := range pruned {
+		if signed[cs.TenantID] {
+			continue
+		}
+		signed[cs.TenantID] = true
+
+		if err := s.signMetadata(ctx, cs.TenantID); err != nil {
+			log.Error(err, "failed to sign metadata after pruning", "tenant", cs.TenantID)
+		}
+	}
+
+	return pruned, nil
+}
+
+// OwnsIssuer reports whether the service may replace or remove the stored issuer of the tenant
+func (s IssuerService) OwnsIssuer(ctx context.Context, tenantID string) (bool, error) {
+	issuer, err := s.store.GetIssuerRecord(ctx, tenantID)
+	if err != nil {
+		return false, err
+	}
+
+	return s.owns(issuer.Source), nil
+}
+
+// ListTenants returns all tenants with stored issuer metadata
+func (s IssuerService) ListTenants(ctx context.Context) ([]string, error) {
+	return s.store.ListTenants(ctx)
+}
+
+// Reconcile compares the stored records of the source of the service with the registrations received since the
+// given time. Credential configurations which were not registered again are removed, if prune is set. Records
+// of other sources, e.g. created via the admin API, are neither reported nor removed. A missing issuer is only
+// reported, as it is usually not announced by the plugins.
+func (s IssuerService) Reconcile(ctx context.Context, tenantID string, since time.Time, prune bool) (*Reconciliation, error) {
+	log := ctxPkg.GetLogger(ctx)
+
+	result := &Reconciliation{TenantID: tenantID}
+
+	issuer, err := s.store.GetIssuerRecord(ctx, tenantID)
+	if err != nil && !errors.Is(err, database.ErrNotFound) {
+		return nil, err
+	}
+
+	if issuer != nil && issuer.Source == s.source && issuer.LastSeen.Before(since) {
+		result.IssuerMissing = true
+	}
+
+	configurations, err := s.store.GetConfigurationsRecord(ctx, tenantID)
+	if err != nil && !errors.Is(err, database.ErrNotFound) {
+		return nil, err
+	}
+
+	for _, configuration := range configurations {
+		if configuration.Source == s.source && configuration.LastSeen.Before(since) {
+			result.MissingConfigurations = append(result.MissingConfigurations, configuration.CredentialConfigurationID)
+		}
+	}
+
+	if !prune || len(result.MissingConfigurations) == 0 {
+		return result, nil
+	}
+
+	for _, id := range result.MissingConfigurations {
+		if err := s.store.DeleteConfigurationRecord(ctx, tenantID, id); err != nil && !errors.Is(err, database.ErrNotFound) {
+			log.Error(err, "failed to prune missing credential configuration", "tenant", tenantID, "configuration", id)
+			return result, err
+		}
+	}
+	result.Pruned = true
+
+	return result, s.signMetadata(ctx, tenantID)
+}
+
+// signMetadata (re-)signs the metadata of the tenant, if signing is enabled and the content changed since
+// the last signature. Tenants without issuer record (only configurations registered yet) are skipped.
+func (s IssuerService) signMetadata(ctx context.Context, tenantID string) error {
+	if s.signer == nil {
+		return nil
+	}
+
+	log := ctxPkg.GetLogger(ctx)
+
+	record, err := s.store.GetIssuerRecord(ctx, tenantID)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	metadata := issuerMetadata(record, false)
+	metadata.SignedMetadata = nil
+
+	digest, err := signer.Digest(metadata)
+	if err != nil {
+		return err
+	}
+
+	if record.SignedMetadataDigest != nil && *record.SignedMetadataDigest == digest {
+		return nil
+	}
+
+	signedMetadata, err := s.signer.SignIssuerMetadata(ctx, tenantID, *metadata)
+	if err != nil {
+		log.Error(err, "failed to sign issuer metadata", "tenant", tenantID)
+		return err
+	}
+
+	if err := s.store.UpdateSignedMetadata(ctx, tenantID, signedMetadata, digest); err != nil {
+		log.Error(err, "failed to store signed metadata", "tenant", tenantID)
+		return err
+	}
+
+	log.Info("signed issuer metadata", "tenant", tenantID, "digest", digest)
+
+	return nil
+}
+
+// GetOpenIDConfiguration returns the stored OpenID Connect discovery document of the tenant
+func (s IssuerService) GetOpenIDConfiguration(ctx context.Context, tenantID string) (*oauth.OpenIdConfiguration, error) {
+	record, err := s.store.GetOpenIDConfigurationRecord(ctx, tenantID)
+	if err != nil {
+		if !errors.Is(err, database.ErrNotFound) {
+			ctxPkg.GetLogger(ctx).Error(err, "failed to get openid configuration record")
+		}
+		return nil, err
+	}
+
+	return &record.Configuration, nil
+}
+
+// UpsertOpenIDConfiguration will store the given discovery document or, if it already exists, replace the existing record
+func (s IssuerService) UpsertOpenIDConfiguration(ctx context.Context, tenantID string, configuration oauth.OpenIdConfiguration) error {
+	if configuration.Issuer == "" {
+		return errors.New("openid configuration without issuer")
+	}
+
+	now := time.Now()
+	record := issuers.OpenIDConfiguration{
+		TenantID:      tenantID,
+		Configuration: configuration,
+		FirstSeen:     now,
+		LastSeen:      now,
+	}
+
+	if err := s.store.UpsertOpenIDConfigurationRecord(ctx, record); err != nil {
+		ctxPkg.GetLogger(ctx).Error(err, "failed to upsert openid configuration")
+		return err
+	}
+
+	return nil
+}
+
+// GetJwtVcIssuer returns the SD-JWT VC issuer metadata of the tenant. If the stored metadata does not name
+// an issuer, the credential issuer of the stored issuer record is used.
+func (s IssuerService) GetJwtVcIssuer(ctx context.Context, tenantID string) (*types.JwtVcIssuerMetadata, error) {
+	record, err := s.store.GetJwtVcIssuerRecord(ctx, tenantID)
+	if err != nil {
+		if !errors.Is(err, database.ErrNotFound) {
+			ctxPkg.GetLogger(ctx).Error(err, "failed to get jwt-vc-issuer record")
+		}
+		return nil, err
+	}
+
+	metadata := record.Metadata
+	if metadata.Issuer == "" {
+		issuer, err := s.store.GetIssuerRecord(ctx, tenantID)
+		if err != nil {
+			return nil, err
+		}
+
+		metadata.Issuer = issuer.CredentialIssuer
+	}
+
+	return &metadata, nil
+}
+
+// UpsertJwtVcIssuer will store the given SD-JWT VC issuer metadata or, if it already exists, replace the existing record
+func (s IssuerService) UpsertJwtVcIssuer(ctx context.Context, tenantID string, metadata types.JwtVcIssuerMetadata) error {
+	if err := metadata.Validate(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	record := issuers.JwtVcIssuer{
+		TenantID:  tenantID,
+		Metadata:  metadata,
+		FirstSeen: now,
+		LastSeen:  now,
+	}
+
+	if err := s.store.UpsertJwtVcIssuerRecord(ctx, record); err != nil {
+		ctxPkg.GetLogger(ctx).Error(err, "failed to upsert jwt-vc-issuer")
+		return err
+	}
+
 	return nil
 }
@@ -0,0 +1,402 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-library/model/credential"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/database"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/database/issuers"
+)
+
+// memoryStore keeps the records of a single tenant, methods the tests do not use panic
+type memoryStore struct {
+	issuers.Store
+	issuer         *issuers.Issuer
+	configurations map[string]issuers.CredentialsSupported
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{configurations: make(map[string]issuers.CredentialsSupported)}
+}
+
+func (m *memoryStore) GetIssuerRecord(_ context.Context, _ string) (*issuers.Issuer, error) {
+	if m.issuer == nil {
+		return nil, database.ErrNotFound
+	}
+
+	issuer := *m.issuer
+	issuer.CredentialsSupported, _ = m.GetConfigurationsRecord(context.Background(), issuer.TenantID)
+	return &issuer, nil
+}
+
+func (m *memoryStore) GetConfigurationsRecord(_ context.Context, _ string) ([]issuers.CredentialsSupported, error) {
+	if len(m.configurations) == 0 {
+		return nil, database.ErrNotFound
+	}
+
+	configurations := make([]issuers.CredentialsSupported, 0, len(m.configurations))
+	for _, c := range m.configurations {
+		configurations = append(configurations, c)
+	}
+	return configurations, nil
+}
+
+func (m *memoryStore) InsertIssuerRecord(_ context.Context, issuer issuers.Issuer) error {
+	m.issuer = &issuer
+	return m.UpdateConfigurationsSupported(context.Background(), issuer.TenantID, issuer.CredentialsSupported)
+}
+
+func (m *memoryStore) ReplaceIssuerRecord(_ context.Context, issuer issuers.Issuer) error {
+	if m.issuer == nil {
+		return database.ErrNotFound
+	}
+
+	issuer.FirstSeen = m.issuer.FirstSeen
+	m.issuer = &issuer
+	return m.UpdateConfigurationsSupported(context.Background(), issuer.TenantID, issuer.CredentialsSupported)
+}
+
+func (m *memoryStore) UpdateConfigurationsSupported(_ context.Context, tenantID string, update []issuers.CredentialsSupported) error {
+	for _, c := range update {
+		c.TenantID = tenantID
+		m.configurations[c.CredentialConfigurationID] = c
+	}
+	return nil
+}
+
+func TestUpsertIssuerKeepsFirstSeen(t *testing.T) {
+	firstSeen := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		stored        []issuers.CredentialsSupported
+		configuration string
+		wantFirstSeen bool
+	}{
+		{name: "new configuration", configuration: "pid"},
+		{
+			name:          "registered again",
+			stored:        []issuers.CredentialsSupported{{CredentialConfigurationID: "pid", FirstSeen: firstSeen}},
+			configuration: "pid",
+			wantFirstSeen: true,
+		},
+		{
+			name:          "other configuration stored",
+			stored:        []issuers.CredentialsSupported{{CredentialConfigurationID: "mdl", FirstSeen: firstSeen}},
+			configuration: "pid",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemoryStore()
+			store.issuer = &issuers.Issuer{TenantID: "tenant", CredentialIssuer: "https://issuer.example", FirstSeen: firstSeen}
+			_ = store.UpdateConfigurationsSupported(context.Background(), "tenant", tt.stored)
+
+			svc := NewIssuerService(store, nil)
+			err := svc.UpsertIssuer(context.Background(), "tenant", credential.IssuerMetadata{
+				CredentialIssuer:                  "https://issuer.example",
+				CredentialConfigurationsSupported: map[string]credential.CredentialConfiguration{tt.configuration: {Format: "vc+sd-jwt"}},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := store.configurations[tt.configuration].FirstSeen
+			if tt.wantFirstSeen != got.Equal(firstSeen) {
+				t.Fatalf("expected first seen kept %v, got %s", tt.wantFirstSeen, got)
+			}
+		})
+	}
+}
+
+func (m *memoryStore) DeleteIssuerRecord(_ context.Context, _ string, configurationSources []string) error {
+	if m.issuer == nil {
+		return database.ErrNotFound
+	}
+
+	m.issuer = nil
+	for id, c := range m.configurations {
+		if configurationSources == nil || slices.Contains(configurationSources, c.Source) {
+			delete(m.configurations, id)
+		}
+	}
+	return nil
+}
+
+func (m *memoryStore) DeleteConfigurationRecord(_ context.Context, _, configurationID string) error {
+	if _, ok := m.configurations[configurationID]; !ok {
+		return database.ErrNotFound
+	}
+
+	delete(m.configurations, configurationID)
+	return nil
+}
+
+func TestIssuerServiceOwnership(t *testing.T) {
+	metadata := credential.IssuerMetadata{
+		CredentialIssuer:                  "https://issuer.example",
+		CredentialConfigurationsSupported: map[string]credential.CredentialConfiguration{"pid": {Format: "vc+sd-jwt"}},
+	}
+
+	tests := []struct {
+		name          string
+		source        string
+		storedSource  string
+		operation     func(svc IssuerService) error
+		wantErr       error
+		wantIssuer    bool
+		wantSource    string
+		wantRemaining []string
+	}{
+		{
+			name:          "broadcast deregisters own issuer",
+			source:        issuers.SourceBroadcast,
+			storedSource:  issuers.SourceBroadcast,
+			operation:     func(svc IssuerService) error { return svc.DeleteIssuer(context.Background(), "tenant") },
+			wantRemaining: []string{"admin"},
+		},
+		{
+			name:          "broadcast deregisters issuer without source",
+			source:        issuers.SourceBroadcast,
+			operation:     func(svc IssuerService) error { return svc.DeleteIssuer(context.Background(), "tenant") },
+			wantRemaining: []string{"admin"},
+		},
+		{
+			name:          "broadcast deregisters admin issuer",
+			source:        issuers.SourceBroadcast,
+			storedSource:  issuers.SourceAdmin,
+			operation:     func(svc IssuerService) error { return svc.DeleteIssuer(context.Background(), "tenant") },
+			wantErr:       ErrForeignRecord,
+			wantIssuer:    true,
+			wantSource:    issuers.SourceAdmin,
+			wantRemaining: []string{"admin", "own"},
+		},
+		{
+			name:          "admin deletes broadcast issuer",
+			source:        issuers.SourceAdmin,
+			storedSource:  issuers.SourceBroadcast,
+			operation:     func(svc IssuerService) error { return svc.DeleteIssuer(context.Background(), "tenant") },
+			wantRemaining: []string{},
+		},
+		{
+			name:          "broadcast deregisters admin configuration",
+			source:        issuers.SourceBroadcast,
+			storedSource:  issuers.SourceBroadcast,
+			operation:     func(svc IssuerService) error { return svc.DeleteConfiguration(context.Background(), "tenant", "admin") },
+			wantErr:       ErrForeignRecord,
+			wantIssuer:    true,
+			wantSource:    issuers.SourceBroadcast,
+			wantRemaining: []string{"admin", "own"},
+		},
+		{
+			name:          "broadcast deregisters own configuration",
+			source:        issuers.SourceBroadcast,
+			storedSource:  issuers.SourceBroadcast,
+			operation:     func(svc IssuerService) error { return svc.DeleteConfiguration(context.Background(), "tenant", "own") },
+			wantIssuer:    true,
+			wantSource:    issuers.SourceBroadcast,
+			wantRemaining: []string{"admin"},
+		},
+		{
+			name:          "broadcast registers admin issuer",
+			source:        issuers.SourceBroadcast,
+			storedSource:  issuers.SourceAdmin,
+			operation:     func(svc IssuerService) error { return svc.UpsertIssuer(context.Background(), "tenant", metadata) },
+			wantErr:       ErrForeignRecord,
+			wantIssuer:    true,
+			wantSource:    issuers.SourceAdmin,
+			wantRemaining: []string{"admin", "own"},
+		},
+		{
+			name:         "broadcast registers admin configuration",
+			source:       issuers.SourceBroadcast,
+			storedSource: issuers.SourceBroadcast,
+			operation: func(svc IssuerService) error {
+				return svc.UpsertConfiguration(context.Background(), "tenant", "admin", credential.CredentialConfiguration{})
+			},
+			wantErr:       ErrForeignRecord,
+			wantIssuer:    true,
+			wantSource:    issuers.SourceBroadcast,
+			wantRemaining: []string{"admin", "own"},
+		},
+		{
+			name:          "admin replaces broadcast issuer",
+			source:        issuers.SourceAdmin,
+			storedSource:  issuers.SourceBroadcast,
+			operation:     func(svc IssuerService) error { return svc.UpsertIssuer(context.Background(), "tenant", metadata) },
+			wantIssuer:    true,
+			wantSource:    issuers.SourceAdmin,
+			wantRemaining: []string{"admin", "own", "pid"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemoryStore()
+			store.issuer = &issuers.Issuer{TenantID: "tenant", CredentialIssuer: "https://issuer.example", Source: tt.storedSource}
+			_ = store.UpdateConfigurationsSupported(context.Background(), "tenant", []issuers.CredentialsSupported{
+				{CredentialConfigurationID: "own", Source: tt.storedSource},
+				{CredentialConfigurationID: "admin", Source: issuers.SourceAdmin},
+			})
+
+			svc := NewIssuerService(store, nil).WithSource(tt.source)
+			if err := tt.operation(svc); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+
+			if (store.issuer != nil) != tt.wantIssuer {
+				t.Fatalf("expected issuer %v, got %v", tt.wantIssuer, store.issuer)
+			}
+			if store.issuer != nil && store.issuer.Source != tt.wantSource {
+				t.Fatalf("expected issuer of source %s, got %s", tt.wantSource, store.issuer.Source)
+			}
+
+			remaining := slices.Sorted(maps.Keys(store.configurations))
+			if !slices.Equal(remaining, tt.wantRemaining) {
+				t.Fatalf("expected configurations %v, got %v", tt.wantRemaining, remaining)
+			}
+		})
+	}
+}
+
+func (m *memoryStore) DeleteExpiredConfigurations(_ context.Context, source string, before time.Time) ([]issuers.CredentialsSupported, error) {
+	var pruned []issuers.CredentialsSupported
+	for id, c := range m.configurations {
+		if c.Source == source && c.LastSeen.Before(before) {
+			pruned = append(pruned, c)
+			delete(m.configurations, id)
+		}
+	}
+	return pruned, nil
+}
+
+func TestPruneExpiredConfigurations(t *testing.T) {
+	before := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	expired := before.Add(-time.Hour)
+
+	tests := []struct {
+		name          string
+		source        string
+		wantPruned    []string
+		wantRemaining []string
+	}{
+		{name: "broadcast", source: issuers.SourceBroadcast, wantPruned: []string{"expired"}, wantRemaining: []string{"admin", "fresh"}},
+		{name: "admin", source: issuers.SourceAdmin, wantPruned: []string{"admin"}, wantRemaining: []string{"expired", "fresh"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemoryStore()
+			_ = store.UpdateConfigurationsSupported(context.Background(), "tenant", []issuers.CredentialsSupported{
+				{CredentialConfigurationID: "expired", Source: issuers.SourceBroadcast, LastSeen: expired},
+				{CredentialConfigurationID: "fresh", Source: issuers.SourceBroadcast, LastSeen: before.Add(time.Hour)},
+				{CredentialConfigurationID: "admin", Source: issuers.SourceAdmin, LastSeen: expired},
+			})
+
+			svc := NewIssuerService(store, nil).WithSource(tt.source)
+			pruned, err := svc.PruneExpiredConfigurations(context.Background(), before)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			prunedIDs := make([]string, 0, len(pruned))
+			for _, c := range pruned {
+				prunedIDs = append(prunedIDs, c.CredentialConfigurationID)
+			}
+			if !slices.Equal(prunedIDs, tt.wantPruned) {
+				t.Fatalf("expected pruned configurations %v, got %v", tt.wantPruned, prunedIDs)
+			}
+
+			remaining := slices.Sorted(maps.Keys(store.configurations))
+			if !slices.Equal(remaining, tt.wantRemaining) {
+				t.Fatalf("expected configurations %v, got %v", tt.wantRemaining, remaining)
+			}
+		})
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	since := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	stale := since.Add(-time.Hour)
+	announced := since.Add(time.Minute)
+
+	tests := []struct {
+		name              string
+		issuerSource      string
+		issuerLastSeen    time.Time
+		prune             bool
+		wantIssuerMissing bool
+		wantMissing       []string
+		wantRemaining     []string
+	}{
+		{
+			name:           "announced",
+			issuerSource:   issuers.SourceBroadcast,
+			issuerLastSeen: announced,
+			wantMissing:    []string{"stale"},
+			wantRemaining:  []string{"admin", "announced", "stale"},
+		},
+		{
+			name:              "issuer missing",
+			issuerSource:      issuers.SourceBroadcast,
+			issuerLastSeen:    stale,
+			wantIssuerMissing: true,
+			wantMissing:       []string{"stale"},
+			wantRemaining:     []string{"admin", "announced", "stale"},
+		},
+		{
+			name:           "admin issuer is not reported",
+			issuerSource:   issuers.SourceAdmin,
+			issuerLastSeen: stale,
+			wantMissing:    []string{"stale"},
+			wantRemaining:  []string{"admin", "announced", "stale"},
+		},
+		{
+			name:           "prune",
+			issuerSource:   issuers.SourceBroadcast,
+			issuerLastSeen: announced,
+			prune:          true,
+			wantMissing:    []string{"stale"},
+			wantRemaining:  []string{"admin", "announced"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemoryStore()
+			store.issuer = &issuers.Issuer{TenantID: "tenant", Source: tt.issuerSource, LastSeen: tt.issuerLastSeen}
+			_ = store.UpdateConfigurationsSupported(context.Background(), "tenant", []issuers.CredentialsSupported{
+				{CredentialConfigurationID: "stale", Source: issuers.SourceBroadcast, LastSeen: stale},
+				{CredentialConfigurationID: "announced", Source: issuers.SourceBroadcast, LastSeen: announced},
+				{CredentialConfigurationID: "admin", Source: issuers.SourceAdmin, LastSeen: stale},
+			})
+
+			result, err := NewIssuerService(store, nil).Reconcile(context.Background(), "tenant", since, tt.prune)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result.IssuerMissing != tt.wantIssuerMissing {
+				t.Fatalf("expected issuer missing %v, got %v", tt.wantIssuerMissing, result.IssuerMissing)
+			}
+			if !slices.Equal(result.MissingConfigurations, tt.wantMissing) {
+				t.Fatalf("expected missing configurations %v, got %v", tt.wantMissing, result.MissingConfigurations)
+			}
+			if result.Pruned != tt.prune {
+				t.Fatalf("expected pruned %v, got %v", tt.prune, result.Pruned)
+			}
+
+			remaining := slices.Sorted(maps.Keys(store.configurations))
+			if !slices.Equal(remaining, tt.wantRemaining) {
+				t.Fatalf("expected configurations %v, got %v", tt.wantRemaining, remaining)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	ctxPkg "github.com/eclipse-xfsc/microservice-core-go/pkg/ctx"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/database"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/database/verifiers"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/types"
+)
+
+type VerifierService struct {
+	store verifiers.Store
+}
+
+func NewVerifierService(store verifiers.Store) VerifierService {
+	return VerifierService{store: store}
+}
+
+func (s VerifierService) GetVerifier(ctx context.Context, tenantID string) (*types.VerifierMetadata, error) {
+	record, err := s.store.GetVerifierRecord(ctx, tenantID)
+	if err != nil {
+		if !errors.Is(err, database.ErrNotFound) {
+			ctxPkg.GetLogger(ctx).Error(err, "failed to get verifier record")
+		}
+		return nil, err
+	}
+
+	return &record.Metadata, nil
+}
+
+// UpsertVerifier will store the given verifier metadata or, if it already exists, replace the existing record
+func (s VerifierService) UpsertVerifier(ctx context.Context, tenantID string, metadata types.VerifierMetadata) error {
+	if err := metadata.Validate(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	record := verifiers.Verifier{
+		TenantID:  tenantID,
+		ClientID:  metadata.ClientID,
+		Metadata:  metadata,
+		FirstSeen: now,
+		LastSeen:  now,
+	}
+
+	if err := s.store.UpsertVerifierRecord(ctx, record); err != nil {
+		ctxPkg.GetLogger(ctx).Error(err, "failed to upsert verifier")
+		return err
+	}
+
+	return nil
+}
+
+// DeleteVerifier removes the verifier metadata of the tenant
+func (s VerifierService) DeleteVerifier(ctx context.Context, tenantID string) error {
+	if err := s.store.DeleteVerifierRecord(ctx, tenantID); err != nil {
+		if !errors.Is(err, database.ErrNotFound) {
+			ctxPkg.GetLogger(ctx).Error(err, "failed to delete verifier")
+		}
+		return err
+	}
+
+	return nil
+}
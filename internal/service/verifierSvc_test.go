@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/database/verifiers"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/types"
+)
+
+// verifierStore records the upserted verifiers, methods the tests do not use panic
+type verifierStore struct {
+	verifiers.Store
+	upserted []verifiers.Verifier
+}
+
+func (v *verifierStore) UpsertVerifierRecord(_ context.Context, verifier verifiers.Verifier) error {
+	v.upserted = append(v.upserted, verifier)
+	return nil
+}
+
+func TestUpsertVerifier(t *testing.T) {
+	formats := map[string]interface{}{"vc+sd-jwt": map[string]interface{}{"sd-jwt_alg_values": []string{"ES256"}}}
+
+	tests := []struct {
+		name     string
+		metadata types.VerifierMetadata
+		wantErr  bool
+	}{
+		{name: "valid", metadata: types.VerifierMetadata{ClientID: "https://verifier.example", VpFormatsSupported: formats}},
+		{name: "without client_id", metadata: types.VerifierMetadata{VpFormatsSupported: formats}, wantErr: true},
+		{name: "without vp_formats_supported", metadata: types.VerifierMetadata{ClientID: "https://verifier.example"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &verifierStore{}
+			svc := NewVerifierService(store)
+
+			err := svc.UpsertVerifier(context.Background(), "tenant", tt.metadata)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+
+			if tt.wantErr {
+				if len(store.upserted) != 0 {
+					t.Fatalf("expected invalid metadata not to be stored, got %v", store.upserted)
+				}
+				return
+			}
+
+			if len(store.upserted) != 1 {
+				t.Fatalf("expected one stored verifier, got %d", len(store.upserted))
+			}
+			if got := store.upserted[0]; got.TenantID != "tenant" || got.ClientID != tt.metadata.ClientID {
+				t.Fatalf("expected verifier %s of tenant, got %s of %s", tt.metadata.ClientID, got.ClientID, got.TenantID)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package signer
+
+import (
+	"context"
+	"sync"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-library/model/credential"
+)
+
+// cacheSize is the number of signatures kept per tenant. The metadata of a tenant varies by request, e.g. by
+// the localization and the enrichment headers.
+const cacheSize = 8
+
+// Cache signs the issuer metadata of a tenant and reuses the signature as long as the metadata does not change.
+// It is used by importers which assemble the metadata on every request and by the gateway.
+type Cache struct {
+	signer *Signer
+
+	mu      sync.Mutex
+	entries map[string][]cacheEntry
+}
+
+type cacheEntry struct {
+	digest string
+	jwt    string
+}
+
+func NewCache(signer *Signer) *Cache {
+	return &Cache{
+		signer:  signer,
+		entries: make(map[string][]cacheEntry),
+	}
+}
+
+// SignIssuerMetadata sets signed_metadata of the issuer
+func (c *Cache) SignIssuerMetadata(ctx context.Context, tenantID string, issuer *credential.IssuerMetadata) error {
+	jwt, err := c.Sign(ctx, tenantID, *issuer)
+	if err != nil {
+		return err
+	}
+
+	issuer.SignedMetadata = &jwt
+
+	return nil
+}
+
+// Sign returns the JWT of the issuer metadata (see Signer.SignIssuerMetadata), which is only created again,
+// when the metadata changed. The provider is called without holding the lock, so a slow signature doesn't
+// block the other tenants; concurrent misses of the same metadata may sign twice.
+func (c *Cache) Sign(ctx context.Context, tenantID string, issuer credential.IssuerMetadata) (string, error) {
+	issuer.SignedMetadata = nil
+
+	digest, err := Digest(issuer)
+	if err != nil {
+		return "", err
+	}
+
+	if jwt, ok := c.lookup(tenantID, digest); ok {
+		return jwt, nil
+	}
+
+	jwt, err := c.signer.SignIssuerMetadata(ctx, tenantID, issuer)
+	if err != nil {
+		c.signer.log.Error(err, "failed to sign issuer metadata", "tenant", tenantID)
+		return "", err
+	}
+
+	c.insert(tenantID, digest, jwt)
+
+	return jwt, nil
+}
+
+// lookup returns the cached JWT of the digest and moves it to the front
+func (c *Cache) lookup(tenantID, digest string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries := c.entries[tenantID]
+	for i, entry := range entries {
+		if entry.digest == digest {
+			// most recently used first
+			copy(entries[1:i+1], entries[:i])
+			entries[0] = entry
+
+			return entry.jwt, true
+		}
+	}
+
+	return "", false
+}
+
+// insert adds the JWT in front, the least recently used entry is dropped from a full cache
+func (c *Cache) insert(tenantID, digest, jwt string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries := c.entries[tenantID]
+	for i, entry := range entries {
+		// signed concurrently by another request
+		if entry.digest == digest {
+			entries = append(entries[:i], entries[i+1:]...)
+			break
+		}
+	}
+
+	if len(entries) < cacheSize {
+		entries = append(entries, cacheEntry{})
+	}
+	copy(entries[1:], entries)
+	entries[0] = cacheEntry{digest: digest, jwt: jwt}
+	c.entries[tenantID] = entries
+}
@@ -0,0 +1,108 @@
+package signer
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"io"
+	"testing"
+	"time"
+
+	cryptoTypes "github.com/eclipse-xfsc/crypto-provider-core/types"
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/logr"
+	"github.com/eclipse-xfsc/oid4-vci-vp-library/model/credential"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/config"
+)
+
+// blockingProvider blocks the signatures of a tenant until release is closed
+type blockingProvider struct {
+	*fakeProvider
+
+	tenant  string
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (p *blockingProvider) Sign(identifier cryptoTypes.CryptoIdentifier, data []byte) ([]byte, error) {
+	if identifier.CryptoContext.Namespace == p.tenant {
+		select {
+		case p.entered <- struct{}{}:
+		default:
+		}
+		<-p.release
+	}
+
+	return p.fakeProvider.Sign(identifier, data)
+}
+
+func TestCacheSign(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	logger, err := logr.New("error", false, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provider := &blockingProvider{
+		fakeProvider: &fakeProvider{key: key, sign: signDER(crypto.SHA256)},
+		entered:      make(chan struct{}, 1),
+		release:      make(chan struct{}),
+	}
+	cache := NewCache(New(config.SigningConfig{KeyID: "metadata"}, provider, *logger))
+
+	issuer := credential.IssuerMetadata{CredentialIssuer: "https://issuer.example"}
+	other := credential.IssuerMetadata{CredentialIssuer: "https://other.example"}
+
+	first, err := cache.Sign(context.Background(), "fast", issuer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := cache.Sign(context.Background(), "fast", issuer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != first {
+		t.Fatal("expected the cached JWT for unchanged metadata")
+	}
+
+	changed, err := cache.Sign(context.Background(), "fast", other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed == first {
+		t.Fatal("expected a new JWT for changed metadata")
+	}
+
+	// a signature which hangs in the provider must not block the cache of other tenants
+	provider.tenant = "slow"
+	done := make(chan error)
+	go func() {
+		_, err := cache.Sign(context.Background(), "slow", issuer)
+		done <- err
+	}()
+	<-provider.entered
+
+	result := make(chan string)
+	go func() {
+		jwt, _ := cache.Sign(context.Background(), "fast", issuer)
+		result <- jwt
+	}()
+
+	select {
+	case jwt := <-result:
+		if jwt != first {
+			t.Fatal("expected the cached JWT while another tenant signs")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("cache blocked by the signature of another tenant")
+	}
+
+	close(provider.release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,266 @@
+package signer
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	cryptoTypes "github.com/eclipse-xfsc/crypto-provider-core/types"
+)
+
+// probeMessage is signed once per key to find out, which signature scheme the provider uses for it
+var probeMessage = []byte("metadata signing key probe")
+
+var errUnverifiable = errors.New("signature of the crypto provider does not verify with the public key")
+
+// signingKey is the public part of a tenant key together with the JWS algorithm the provider signs with
+type signingKey struct {
+	alg    string
+	kid    string
+	public crypto.PublicKey
+	hash   crypto.Hash
+	chain  []string
+}
+
+// resolveKey determines the JWS algorithm of the key by a probe signature. The key type is not sufficient,
+// because providers differ in the RSA padding and the hash they use.
+func (s *Signer) resolveKey(identifier cryptoTypes.CryptoIdentifier, cryptoKey *cryptoTypes.CryptoKey) (*signingKey, error) {
+	public, err := publicKey(cryptoKey.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	probe, err := s.provider.Sign(identifier, probeMessage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign probe: %w", err)
+	}
+
+	key := &signingKey{
+		public: public,
+		chain:  certificateChain(cryptoKey.Key),
+	}
+
+	key.kid, err = thumbprint(public)
+	if err != nil {
+		return nil, err
+	}
+
+	switch public := public.(type) {
+	case *ecdsa.PublicKey:
+		key.alg, key.hash, err = ecdsaAlgorithm(public.Curve)
+		if err != nil {
+			return nil, err
+		}
+	case ed25519.PublicKey:
+		key.alg = "EdDSA"
+	case *rsa.PublicKey:
+		// PS* is preferred by OID4VCI, RS* is accepted for providers which only support PKCS #1 v1.5
+		for _, candidate := range []struct {
+			alg  string
+			hash crypto.Hash
+		}{
+			{"PS256", crypto.SHA256}, {"PS384", crypto.SHA384}, {"PS512", crypto.SHA512},
+			{"RS256", crypto.SHA256}, {"RS384", crypto.SHA384}, {"RS512", crypto.SHA512},
+		} {
+			key.alg, key.hash = candidate.alg, candidate.hash
+			if _, err := key.verify(probeMessage, probe); err == nil {
+				return key, nil
+			}
+		}
+
+		return nil, fmt.Errorf("%w: no RSA scheme of JWS matches", errUnverifiable)
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", public)
+	}
+
+	if _, err := key.verify(probeMessage, probe); err != nil {
+		return nil, fmt.Errorf("%s: %w", key.alg, err)
+	}
+
+	return key, nil
+}
+
+// verify checks the signature of the provider over data and returns it in the encoding of JWS. ECDSA
+// signatures are converted from ASN.1 DER to R || S (RFC 7518, section 3.4).
+func (k *signingKey) verify(data, signature []byte) ([]byte, error) {
+	switch public := k.public.(type) {
+	case *ecdsa.PublicKey:
+		size := (public.Curve.Params().BitSize + 7) / 8
+
+		for _, rs := range ecdsaSignatures(signature, size) {
+			if !ecdsa.Verify(public, digest(k.hash, data), rs[0], rs[1]) {
+				continue
+			}
+
+			encoded := make([]byte, 2*size)
+			rs[0].FillBytes(encoded[:size])
+			rs[1].FillBytes(encoded[size:])
+
+			return encoded, nil
+		}
+
+		return nil, errUnverifiable
+	case ed25519.PublicKey:
+		if !ed25519.Verify(public, data, signature) {
+			return nil, errUnverifiable
+		}
+
+		return signature, nil
+	case *rsa.PublicKey:
+		var err error
+		if strings.HasPrefix(k.alg, "PS") {
+			err = rsa.VerifyPSS(public, k.hash, digest(k.hash, data), signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		} else {
+			err = rsa.VerifyPKCS1v15(public, k.hash, digest(k.hash, data), signature)
+		}
+		if err != nil {
+			return nil, errUnverifiable
+		}
+
+		return signature, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", public)
+	}
+}
+
+func ecdsaAlgorithm(curve elliptic.Curve) (string, crypto.Hash, error) {
+	switch curve {
+	case elliptic.P256():
+		return "ES256", crypto.SHA256, nil
+	case elliptic.P384():
+		return "ES384", crypto.SHA384, nil
+	case elliptic.P521():
+		return "ES512", crypto.SHA512, nil
+	default:
+		return "", 0, fmt.Errorf("unsupported curve %s", curve.Params().Name)
+	}
+}
+
+// ecdsaSignatures returns the candidate values of R and S of a signature, as providers return either R || S
+// or ASN.1 DER
+func ecdsaSignatures(signature []byte, size int) [][2]*big.Int {
+	var candidates [][2]*big.Int
+
+	if len(signature) == 2*size {
+		candidates = append(candidates, [2]*big.Int{
+			new(big.Int).SetBytes(signature[:size]),
+			new(big.Int).SetBytes(signature[size:]),
+		})
+	}
+
+	var der struct {
+		R, S *big.Int
+	}
+
+	if rest, err := asn1.Unmarshal(signature, &der); err == nil && len(rest) == 0 && der.R != nil && der.S != nil {
+		candidates = append(candidates, [2]*big.Int{der.R, der.S})
+	}
+
+	return candidates
+}
+
+func digest(hash crypto.Hash, data []byte) []byte {
+	h := hash.New()
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// publicKey returns the public key of a PEM encoded key. A certificate chain is expected to start with the
+// certificate of the key.
+func publicKey(pemData []byte) (crypto.PublicKey, error) {
+	for {
+		var block *pem.Block
+		block, pemData = pem.Decode(pemData)
+		if block == nil {
+			return nil, errors.New("no public key in PEM data")
+		}
+
+		switch block.Type {
+		case "PUBLIC KEY":
+			return x509.ParsePKIXPublicKey(block.Bytes)
+		case "CERTIFICATE":
+			certificate, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, err
+			}
+
+			return certificate.PublicKey, nil
+		}
+	}
+}
+
+// JWK returns the public key as JSON Web Key (RFC 7517) with the thumbprint as kid
+func (k *signingKey) JWK() (map[string]interface{}, error) {
+	jwk, err := publicJWK(k.public)
+	if err != nil {
+		return nil, err
+	}
+
+	jwk["kid"] = k.kid
+	jwk["alg"] = k.alg
+	jwk["use"] = "sig"
+	if len(k.chain) > 0 {
+		jwk["x5c"] = k.chain
+	}
+
+	return jwk, nil
+}
+
+// publicJWK returns the required members of the JWK of a public key (RFC 7518, section 6; RFC 8037)
+func publicJWK(public crypto.PublicKey) (map[string]interface{}, error) {
+	encode := base64.RawURLEncoding.EncodeToString
+
+	switch public := public.(type) {
+	case *ecdsa.PublicKey:
+		size := (public.Curve.Params().BitSize + 7) / 8
+		return map[string]interface{}{
+			"kty": "EC",
+			"crv": public.Curve.Params().Name,
+			"x":   encode(public.X.FillBytes(make([]byte, size))),
+			"y":   encode(public.Y.FillBytes(make([]byte, size))),
+		}, nil
+	case ed25519.PublicKey:
+		return map[string]interface{}{
+			"kty": "OKP",
+			"crv": "Ed25519",
+			"x":   encode(public),
+		}, nil
+	case *rsa.PublicKey:
+		return map[string]interface{}{
+			"kty": "RSA",
+			"n":   encode(public.N.Bytes()),
+			"e":   encode(big.NewInt(int64(public.E)).Bytes()),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", public)
+	}
+}
+
+// thumbprint returns the JWK thumbprint (RFC 7638) of the public key, which identifies the key as kid. The
+// required members are serialized in lexicographic order without whitespace, as json.Marshal does for maps.
+func thumbprint(public crypto.PublicKey) (string, error) {
+	jwk, err := publicJWK(public)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(jwk)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
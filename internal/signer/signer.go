@@ -0,0 +1,296 @@
+package signer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	cryptoTypes "github.com/eclipse-xfsc/crypto-provider-core/types"
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/logr"
+	"github.com/eclipse-xfsc/oid4-vci-vp-library/model/credential"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/config"
+)
+
+// IssuerMetadataType is the typ header of signed credential issuer metadata (OID4VCI, section 12.2.3)
+const IssuerMetadataType = "openidvci-issuer-metadata+jwt"
+
+// ErrNoKey is returned for the keys of tenants which never signed
+var ErrNoKey = errors.New("no signing key")
+
+// Signer creates JWTs over the metadata of a tenant with the tenant specific key of the crypto provider
+type Signer struct {
+	conf     config.SigningConfig
+	provider cryptoTypes.CryptoProvider
+	log      logr.Logger
+
+	mu   sync.Mutex
+	keys map[string]*signingKey
+}
+
+func New(conf config.SigningConfig, provider cryptoTypes.CryptoProvider, logger logr.Logger) *Signer {
+	return &Signer{
+		conf:     conf,
+		provider: provider,
+		log:      logger,
+		keys:     make(map[string]*signingKey),
+	}
+}
+
+// SignIssuerMetadata returns a JWT carrying the given issuer metadata as claims, as used for the
+// signed_metadata parameter (OID4VCI, section 11.2.3). An existing signed_metadata is not part of the claims.
+func (s *Signer) SignIssuerMetadata(ctx context.Context, tenantID string, metadata credential.IssuerMetadata) (string, error) {
+	metadata.SignedMetadata = nil
+
+	claims, err := IssuerMetadataClaims(metadata)
+	if err != nil {
+		return "", err
+	}
+
+	return s.Sign(ctx, tenantID, IssuerMetadataType, claims)
+}
+
+// IssuerMetadataClaims converts the issuer metadata into JWT claims. The credential issuer is the subject
+// (and issuer) of the resulting token.
+func IssuerMetadataClaims(metadata credential.IssuerMetadata) (map[string]interface{}, error) {
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := make(map[string]interface{})
+	if err := json.Unmarshal(data, &claims); err != nil {
+		return nil, err
+	}
+
+	delete(claims, "signed_metadata")
+	claims["sub"] = metadata.CredentialIssuer
+	claims["iss"] = metadata.CredentialIssuer
+	claims["iat"] = time.Now().Unix()
+
+	return claims, nil
+}
+
+// Sign creates a compact JWS over the given claims with the key of the tenant. The key is generated, if it
+// does not exist yet.
+func (s *Signer) Sign(ctx context.Context, tenantID string, typ string, claims map[string]interface{}) (string, error) {
+	identifier := s.identifier(ctx, tenantID)
+
+	encodedClaims, err := encodeSegment(claims)
+	if err != nil {
+		return "", err
+	}
+
+	// a key which was rotated in the provider fails the verification and is resolved again
+	for attempt := 0; ; attempt++ {
+		key, err := s.key(identifier, true)
+		if err != nil {
+			return "", err
+		}
+
+		// the thumbprint identifies the key in the JWKS of the tenant, the key id of the provider is the same
+		// for every tenant
+		header := map[string]interface{}{
+			"alg": key.alg,
+			"typ": typ,
+			"kid": key.kid,
+		}
+
+		if s.conf.X5c {
+			if len(key.chain) == 0 {
+				return "", fmt.Errorf("x5c requested, but key %s of tenant %s has no certificate chain", s.conf.KeyID, tenantID)
+			}
+
+			header["x5c"] = key.chain
+		}
+
+		encodedHeader, err := encodeSegment(header)
+		if err != nil {
+			return "", err
+		}
+
+		signingInput := encodedHeader + "." + encodedClaims
+
+		signature, err := s.provider.Sign(identifier, []byte(signingInput))
+		if err != nil {
+			s.forgetKey(tenantID)
+			return "", fmt.Errorf("failed to sign metadata of tenant %s: %w", tenantID, err)
+		}
+
+		signature, err = key.verify([]byte(signingInput), signature)
+		if err != nil {
+			s.forgetKey(tenantID)
+			if attempt == 0 {
+				continue
+			}
+
+			return "", fmt.Errorf("signature of tenant %s: %w", tenantID, err)
+		}
+
+		return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+	}
+}
+
+// JWKS returns the JSON Web Key Set with the signing key of the tenant, which verifies signed_metadata. Keys are
+// not generated, tenants which never signed have none (ErrNoKey).
+func (s *Signer) JWKS(ctx context.Context, tenantID string) (map[string]interface{}, error) {
+	key, err := s.key(s.identifier(ctx, tenantID), false)
+	if err != nil {
+		return nil, err
+	}
+
+	jwk, err := key.JWK()
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{"keys": []interface{}{jwk}}, nil
+}
+
+// key returns the resolved signing key of the tenant. Keys are resolved once, because that takes several
+// round-trips to the provider. A missing key is generated, if create is set.
+func (s *Signer) key(identifier cryptoTypes.CryptoIdentifier, create bool) (*signingKey, error) {
+	tenantID := identifier.CryptoContext.Namespace
+
+	s.mu.Lock()
+	key, ok := s.keys[tenantID]
+	s.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	cryptoKey, err := s.ensureKey(identifier, create)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err = s.resolveKey(identifier, cryptoKey)
+	if err != nil {
+		return nil, fmt.Errorf("key %s of tenant %s: %w", identifier.KeyId, tenantID, err)
+	}
+
+	s.mu.Lock()
+	s.keys[tenantID] = key
+	s.mu.Unlock()
+
+	return key, nil
+}
+
+func (s *Signer) forgetKey(tenantID string) {
+	s.mu.Lock()
+	delete(s.keys, tenantID)
+	s.mu.Unlock()
+}
+
+func (s *Signer) identifier(ctx context.Context, tenantID string) cryptoTypes.CryptoIdentifier {
+	return cryptoTypes.CryptoIdentifier{
+		KeyId: s.conf.KeyID,
+		CryptoContext: cryptoTypes.CryptoContext{
+			Namespace: tenantID,
+			Group:     s.conf.Group,
+			Context:   ctx,
+			Engine:    s.conf.Engine,
+			Logger:    cryptoLogger{log: s.log},
+		},
+	}
+}
+
+// ensureKey creates crypto context and key of the tenant on first use, if create is set, and returns the
+// (public) key
+func (s *Signer) ensureKey(identifier cryptoTypes.CryptoIdentifier, create bool) (*cryptoTypes.CryptoKey, error) {
+	exists, err := s.provider.IsCryptoContextExisting(identifier.CryptoContext)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists && !create {
+		return nil, ErrNoKey
+	}
+
+	if !exists {
+		if err := s.provider.CreateCryptoContext(identifier.CryptoContext); err != nil {
+			return nil, fmt.Errorf("failed to create crypto context: %w", err)
+		}
+	}
+
+	exists, err = s.provider.IsKeyExisting(identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists && !create {
+		return nil, ErrNoKey
+	}
+
+	if !exists {
+		s.log.Info("generating metadata signing key", "tenant", identifier.CryptoContext.Namespace, "kid", identifier.KeyId)
+
+		err := s.provider.GenerateKey(cryptoTypes.CryptoKeyParameter{
+			Identifier: identifier,
+			KeyType:    cryptoTypes.KeyType(s.conf.KeyType),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate key: %w", err)
+		}
+	}
+
+	return s.provider.GetKey(identifier)
+}
+
+// certificateChain returns the x5c representation of all certificates in the PEM encoded key
+func certificateChain(pemData []byte) []string {
+	chain := make([]string, 0)
+	for {
+		var block *pem.Block
+		block, pemData = pem.Decode(pemData)
+		if block == nil {
+			return chain
+		}
+
+		if block.Type == "CERTIFICATE" {
+			chain = append(chain, base64.StdEncoding.EncodeToString(block.Bytes))
+		}
+	}
+}
+
+func encodeSegment(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+type cryptoLogger struct {
+	log logr.Logger
+}
+
+func (l cryptoLogger) Log(level cryptoTypes.CryptoLogLevel, msg string, err error) {
+	switch {
+	case err != nil:
+		l.log.Error(err, msg, "level", level)
+	case level == cryptoTypes.DEBUG:
+		l.log.Debug(msg)
+	default:
+		l.log.Info(msg, "level", level)
+	}
+}
+
+// Digest returns a stable hash of the JSON representation of v, which is used to detect content changes
+func Digest(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,300 @@
+package signer
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"strings"
+	"testing"
+
+	cryptoTypes "github.com/eclipse-xfsc/crypto-provider-core/types"
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/logr"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/config"
+)
+
+// fakeProvider signs with an in-memory key the way a crypto provider plugin does
+type fakeProvider struct {
+	cryptoTypes.CryptoProvider
+
+	key     crypto.Signer
+	sign    func(key crypto.Signer, data []byte) ([]byte, error)
+	getKeys int
+	missing bool
+}
+
+func (p *fakeProvider) IsCryptoContextExisting(cryptoTypes.CryptoContext) (bool, error) {
+	return true, nil
+}
+
+func (p *fakeProvider) IsKeyExisting(cryptoTypes.CryptoIdentifier) (bool, error) {
+	return !p.missing, nil
+}
+
+func (p *fakeProvider) GetKey(identifier cryptoTypes.CryptoIdentifier) (*cryptoTypes.CryptoKey, error) {
+	p.getKeys++
+
+	der, err := x509.MarshalPKIXPublicKey(p.key.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	return &cryptoTypes.CryptoKey{
+		Key:                pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}),
+		CryptoKeyParameter: cryptoTypes.CryptoKeyParameter{Identifier: identifier},
+	}, nil
+}
+
+func (p *fakeProvider) Sign(_ cryptoTypes.CryptoIdentifier, data []byte) ([]byte, error) {
+	return p.sign(p.key, data)
+}
+
+func hashed(hash crypto.Hash, data []byte) []byte {
+	h := hash.New()
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+func signDER(hash crypto.Hash) func(crypto.Signer, []byte) ([]byte, error) {
+	return func(key crypto.Signer, data []byte) ([]byte, error) {
+		return key.Sign(rand.Reader, hashed(hash, data), hash)
+	}
+}
+
+func signRaw(key crypto.Signer, data []byte) ([]byte, error) {
+	r, s, err := ecdsa.Sign(rand.Reader, key.(*ecdsa.PrivateKey), hashed(crypto.SHA256, data))
+	if err != nil {
+		return nil, err
+	}
+
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+
+	return signature, nil
+}
+
+func signPSS(key crypto.Signer, data []byte) ([]byte, error) {
+	return key.Sign(rand.Reader, hashed(crypto.SHA256, data), &rsa.PSSOptions{
+		SaltLength: rsa.PSSSaltLengthEqualsHash,
+		Hash:       crypto.SHA256,
+	})
+}
+
+func signEd25519(key crypto.Signer, data []byte) ([]byte, error) {
+	return key.Sign(rand.Reader, data, crypto.Hash(0))
+}
+
+// verifyJWT checks the compact JWS like a relying party, independent of the signer
+func verifyJWT(t *testing.T, jwt string, public crypto.PublicKey) string {
+	t.Helper()
+
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		t.Fatalf("expected a compact JWS, got %q", jwt)
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		t.Fatal(err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := []byte(parts[0] + "." + parts[1])
+
+	var valid bool
+	switch header.Alg {
+	case "ES256", "ES384", "ES512":
+		hash := map[string]crypto.Hash{"ES256": crypto.SHA256, "ES384": crypto.SHA384, "ES512": crypto.SHA512}[header.Alg]
+		size := len(signature) / 2
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		valid = ecdsa.Verify(public.(*ecdsa.PublicKey), hashed(hash, input), r, s)
+	case "PS256":
+		valid = rsa.VerifyPSS(public.(*rsa.PublicKey), crypto.SHA256, hashed(crypto.SHA256, input), signature,
+			&rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}) == nil
+	case "RS256":
+		valid = rsa.VerifyPKCS1v15(public.(*rsa.PublicKey), crypto.SHA256, hashed(crypto.SHA256, input), signature) == nil
+	case "EdDSA":
+		valid = ed25519.Verify(public.(ed25519.PublicKey), input, signature)
+	}
+
+	if !valid {
+		t.Fatalf("JWT with alg %s does not verify", header.Alg)
+	}
+
+	return header.Alg
+}
+
+func TestSign(t *testing.T) {
+	p256, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	p384, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	tests := []struct {
+		name string
+		key  crypto.Signer
+		sign func(crypto.Signer, []byte) ([]byte, error)
+		alg  string
+	}{
+		{name: "ECDSA P-256 DER", key: p256, sign: signDER(crypto.SHA256), alg: "ES256"},
+		{name: "ECDSA P-256 R||S", key: p256, sign: signRaw, alg: "ES256"},
+		{name: "ECDSA P-384 DER", key: p384, sign: signDER(crypto.SHA384), alg: "ES384"},
+		{name: "RSA PSS", key: rsaKey, sign: signPSS, alg: "PS256"},
+		{name: "RSA PKCS #1 v1.5", key: rsaKey, sign: signDER(crypto.SHA256), alg: "RS256"},
+		{name: "Ed25519", key: edKey, sign: signEd25519, alg: "EdDSA"},
+	}
+
+	logger, err := logr.New("error", false, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &fakeProvider{key: tt.key, sign: tt.sign}
+			s := New(config.SigningConfig{KeyID: "metadata"}, provider, *logger)
+
+			for i := 0; i < 2; i++ {
+				jwt, err := s.Sign(context.Background(), "tenant", IssuerMetadataType, map[string]interface{}{"sub": "x"})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if alg := verifyJWT(t, jwt, tt.key.Public()); alg != tt.alg {
+					t.Fatalf("expected alg %s, got %s", tt.alg, alg)
+				}
+			}
+
+			if provider.getKeys != 1 {
+				t.Fatalf("expected the key to be resolved once, got %d lookups", provider.getKeys)
+			}
+		})
+	}
+}
+
+func TestSignUnverifiable(t *testing.T) {
+	p384, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+
+	logger, err := logr.New("error", false, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a provider which hashes P-384 signatures with SHA-256 can't be expressed as JWS algorithm
+	provider := &fakeProvider{key: p384, sign: signDER(crypto.SHA256)}
+	s := New(config.SigningConfig{KeyID: "metadata"}, provider, *logger)
+
+	if _, err := s.Sign(context.Background(), "tenant", IssuerMetadataType, map[string]interface{}{}); err == nil {
+		t.Fatal("expected an error for a signature which does not match the algorithm")
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	p256, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	tests := []struct {
+		name    string
+		key     crypto.Signer
+		sign    func(crypto.Signer, []byte) ([]byte, error)
+		kty     string
+		missing bool
+	}{
+		{name: "ECDSA", key: p256, sign: signDER(crypto.SHA256), kty: "EC"},
+		{name: "RSA", key: rsaKey, sign: signPSS, kty: "RSA"},
+		{name: "Ed25519", key: edKey, sign: signEd25519, kty: "OKP"},
+		{name: "no key", key: p256, sign: signDER(crypto.SHA256), missing: true},
+	}
+
+	logger, err := logr.New("error", false, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &fakeProvider{key: tt.key, sign: tt.sign, missing: tt.missing}
+			s := New(config.SigningConfig{KeyID: "metadata"}, provider, *logger)
+
+			jwks, err := s.JWKS(context.Background(), "tenant")
+			if tt.missing {
+				if !errors.Is(err, ErrNoKey) {
+					t.Fatalf("expected ErrNoKey, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			jwk := jwks["keys"].([]interface{})[0].(map[string]interface{})
+			if jwk["kty"] != tt.kty {
+				t.Fatalf("expected kty %s, got %v", tt.kty, jwk["kty"])
+			}
+
+			expected, err := thumbprint(tt.key.Public())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if jwk["kid"] != expected {
+				t.Fatalf("expected kid %s, got %v", expected, jwk["kid"])
+			}
+
+			jwt, err := s.Sign(context.Background(), "tenant", IssuerMetadataType, map[string]interface{}{"sub": "x"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var header struct {
+				Kid string `json:"kid"`
+			}
+			headerJSON, _ := base64.RawURLEncoding.DecodeString(strings.Split(jwt, ".")[0])
+			if err := json.Unmarshal(headerJSON, &header); err != nil {
+				t.Fatal(err)
+			}
+			if header.Kid != jwk["kid"] {
+				t.Fatalf("expected kid header %v, got %s", jwk["kid"], header.Kid)
+			}
+		})
+	}
+}
+
+func TestThumbprint(t *testing.T) {
+	// example of RFC 7638, section 3.1
+	n, _ := base64.RawURLEncoding.DecodeString("0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw")
+	public := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: 65537}
+
+	kid, err := thumbprint(public)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if kid != "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs" {
+		t.Fatalf("unexpected thumbprint %s", kid)
+	}
+}
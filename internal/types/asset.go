@@ -0,0 +1,134 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-library/model/credential"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/common"
+)
+
+const (
+	// MaxAssetSize limits the size of an asset, which is kept in memory or in the database
+	MaxAssetSize = 5 << 20
+	// AssetHashLength is the number of hex digits of the SHA-256 of the content in the URL of an asset
+	AssetHashLength = 16
+	// assetsDir is the directory of the assets in the repository layout, which relative URLs may start with
+	assetsDir = "images"
+)
+
+var assetNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9._-]*$`)
+
+// Asset is a file of a tenant, e.g. a logo, which is served below common.AssetsPath. The URL of an asset
+// contains the hash of its content, so it can be cached forever.
+type Asset struct {
+	Name        string    `json:"name"`
+	ContentType string    `json:"content_type"`
+	Hash        string    `json:"hash"`
+	Size        int       `json:"size"`
+	ModTime     time.Time `json:"modified"`
+	Content     []byte    `json:"-"`
+}
+
+// NewAsset hashes the content and derives the content type from the extension of the name or, for unknown
+// extensions, from the content
+func NewAsset(name string, content []byte, modTime time.Time) Asset {
+	sum := sha256.Sum256(content)
+
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if contentType == "" {
+		contentType = http.DetectContentType(content)
+	}
+
+	return Asset{
+		Name:        name,
+		ContentType: contentType,
+		Hash:        hex.EncodeToString(sum[:])[:AssetHashLength],
+		Size:        len(content),
+		ModTime:     modTime,
+		Content:     content,
+	}
+}
+
+// ValidAssetName reports whether the name can be used for an uploaded asset: a single path segment of
+// letters, digits, dots, dashes and underscores, which does not start with a dot
+func ValidAssetName(name string) bool {
+	return len(name) <= 255 && assetNamePattern.MatchString(name)
+}
+
+// URL returns the content addressed URL of the asset for the base URL of the tenant
+func (a Asset) URL(baseURL string) (string, error) {
+	return url.JoinPath(baseURL, common.AssetsPath, a.Hash, a.Name)
+}
+
+// AssetReference returns the name of the asset which a relative URL refers to, e.g. logo.png or
+// images/logo.png. Absolute URLs are no asset references.
+func AssetReference(uri string) (string, bool) {
+	if uri == "" {
+		return "", false
+	}
+
+	u, err := url.Parse(uri)
+	if err != nil || u.IsAbs() || u.Host != "" || u.RawQuery != "" || u.Fragment != "" || strings.HasPrefix(u.Path, "/") {
+		return "", false
+	}
+
+	name := strings.TrimPrefix(path.Clean(u.Path), assetsDir+"/")
+	if name == "." || name == ".." || strings.HasPrefix(name, "../") {
+		return "", false
+	}
+
+	return name, true
+}
+
+// RewriteLogos replaces relative logo URLs which refer to an asset by the content addressed URL of the asset.
+// The displays are copied before the first change, since they may be shared with the source of the metadata.
+func RewriteLogos(displays []credential.LocalizedCredential, assets map[string]Asset, baseURL string) []credential.LocalizedCredential {
+	rewritten, copied := displays, false
+	for i, display := range displays {
+		name, ok := AssetReference(display.Logo.URL)
+		if !ok {
+			continue
+		}
+
+		asset, ok := assets[name]
+		if !ok {
+			continue
+		}
+
+		uri, err := asset.URL(baseURL)
+		if err != nil {
+			continue
+		}
+
+		if !copied {
+			rewritten, copied = slices.Clone(displays), true
+		}
+		rewritten[i].Logo.URL = uri
+	}
+
+	return rewritten
+}
+
+// MissingLogos returns the relative logo URLs of the displays which refer to no asset
+func MissingLogos(displays []credential.LocalizedCredential, assets map[string]Asset) []string {
+	var missing []string
+	for _, display := range displays {
+		if name, ok := AssetReference(display.Logo.URL); ok {
+			if _, ok := assets[name]; !ok {
+				missing = append(missing, display.Logo.URL)
+			}
+		}
+	}
+
+	return missing
+}
@@ -0,0 +1,74 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-library/model/credential"
+)
+
+func TestAssetReference(t *testing.T) {
+	tests := []struct {
+		uri    string
+		want   string
+		wantOk bool
+	}{
+		{uri: "logo.png", want: "logo.png", wantOk: true},
+		{uri: "images/logo.png", want: "logo.png", wantOk: true},
+		{uri: "images/icons/logo.png", want: "icons/logo.png", wantOk: true},
+		{uri: "./images/logo.png", want: "logo.png", wantOk: true},
+		{uri: "icons/../logo.png", want: "logo.png", wantOk: true},
+		{uri: "logo%20dark.png", want: "logo dark.png", wantOk: true},
+		{uri: ""},
+		{uri: "."},
+		{uri: ".."},
+		{uri: "../logo.png"},
+		{uri: "images/../../logo.png"},
+		{uri: "/logo.png"},
+		{uri: "https://issuer.example/logo.png"},
+		{uri: "//issuer.example/logo.png"},
+		{uri: "data:image/png;base64,iVBORw0KGgo="},
+		{uri: "logo.png?v=1"},
+		{uri: "logo.png#dark"},
+		{uri: "%zz"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			got, ok := AssetReference(tt.uri)
+			if ok != tt.wantOk || got != tt.want {
+				t.Fatalf("expected %q %v, got %q %v", tt.want, tt.wantOk, got, ok)
+			}
+		})
+	}
+}
+
+func TestRewriteLogos(t *testing.T) {
+	logo := NewAsset("logo.png", []byte("logo"), time.Time{})
+	assets := map[string]Asset{"logo.png": logo}
+	url, _ := logo.URL("https://issuer.example/v1/tenants/t1")
+
+	tests := []struct {
+		name string
+		uri  string
+		want string
+	}{
+		{name: "asset", uri: "images/logo.png", want: url},
+		{name: "missing asset", uri: "images/other.png", want: "images/other.png"},
+		{name: "absolute", uri: "https://cdn.example/logo.png", want: "https://cdn.example/logo.png"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			displays := []credential.LocalizedCredential{{Logo: credential.DescriptiveURL{URL: tt.uri}}}
+
+			rewritten := RewriteLogos(displays, assets, "https://issuer.example/v1/tenants/t1")
+			if rewritten[0].Logo.URL != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, rewritten[0].Logo.URL)
+			}
+			if displays[0].Logo.URL != tt.uri {
+				t.Fatalf("source display was modified")
+			}
+		})
+	}
+}
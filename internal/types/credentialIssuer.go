@@ -1 +1,55 @@
 package types
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-library/model/credential"
+)
+
+// ValidateIssuerMetadata checks the fields of the credential issuer metadata which are required to use it
+func ValidateIssuerMetadata(metadata *credential.IssuerMetadata) error {
+	if err := validateURL("credential_issuer", metadata.CredentialIssuer); err != nil {
+		return err
+	}
+
+	if err := validateURL("credential_endpoint", metadata.CredentialEndpoint); err != nil {
+		return err
+	}
+
+	for _, server := range metadata.AuthorizationServers {
+		if err := validateURL("authorization_servers", server); err != nil {
+			return err
+		}
+	}
+
+	for id, configuration := range metadata.CredentialConfigurationsSupported {
+		if err := ValidateCredentialConfiguration(&configuration); err != nil {
+			return fmt.Errorf("credential configuration %s: %w", id, err)
+		}
+	}
+
+	return nil
+}
+
+func ValidateCredentialConfiguration(configuration *credential.CredentialConfiguration) error {
+	if configuration.Format == "" {
+		return errors.New("format is required")
+	}
+
+	return nil
+}
+
+func validateURL(field, value string) error {
+	if value == "" {
+		return fmt.Errorf("%s is required", field)
+	}
+
+	u, err := url.Parse(value)
+	if err != nil || !u.IsAbs() || u.Host == "" {
+		return fmt.Errorf("%s has to be an absolute URL", field)
+	}
+
+	return nil
+}
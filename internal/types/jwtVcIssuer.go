@@ -0,0 +1,24 @@
+package types
+
+import "errors"
+
+// JwtVcIssuerMetadata represents the SD-JWT VC Issuer Metadata (/.well-known/jwt-vc-issuer) which
+// publishes the keys used to sign SD-JWT VCs of an issuer
+type JwtVcIssuerMetadata struct {
+	Issuer  string                 `json:"issuer"`
+	Jwks    map[string]interface{} `json:"jwks,omitempty"`
+	JwksUri string                 `json:"jwks_uri,omitempty"`
+}
+
+// Validate checks that exactly one of jwks and jwks_uri is present, as required by the specification
+func (m JwtVcIssuerMetadata) Validate() error {
+	if len(m.Jwks) == 0 && m.JwksUri == "" {
+		return errors.New("jwt-vc-issuer metadata requires jwks or jwks_uri")
+	}
+
+	if len(m.Jwks) > 0 && m.JwksUri != "" {
+		return errors.New("jwt-vc-issuer metadata must not contain both jwks and jwks_uri")
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package types
+
+import "testing"
+
+func TestJwtVcIssuerMetadataValidate(t *testing.T) {
+	jwks := map[string]interface{}{"keys": []interface{}{map[string]interface{}{"kty": "EC", "crv": "P-256"}}}
+
+	tests := []struct {
+		name     string
+		metadata JwtVcIssuerMetadata
+		wantErr  bool
+	}{
+		{name: "jwks", metadata: JwtVcIssuerMetadata{Issuer: "https://issuer.example", Jwks: jwks}},
+		{name: "jwks_uri", metadata: JwtVcIssuerMetadata{Issuer: "https://issuer.example", JwksUri: "https://issuer.example/jwks"}},
+		{name: "without issuer", metadata: JwtVcIssuerMetadata{JwksUri: "https://issuer.example/jwks"}},
+		{name: "neither", metadata: JwtVcIssuerMetadata{Issuer: "https://issuer.example"}, wantErr: true},
+		{name: "empty jwks", metadata: JwtVcIssuerMetadata{Issuer: "https://issuer.example", Jwks: map[string]interface{}{}}, wantErr: true},
+		{
+			name:     "both",
+			metadata: JwtVcIssuerMetadata{Issuer: "https://issuer.example", Jwks: jwks, JwksUri: "https://issuer.example/jwks"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.metadata.Validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package types
+
+import (
+	"time"
+
+	"github.com/eclipse-xfsc/nats-message-library/common"
+	"github.com/eclipse-xfsc/oid4-vci-vp-library/model/oauth"
+)
+
+// Event types which are not (yet) part of the nats-message-library. They are published
+// on messaging.TopicIssuerRegistration next to the issuer and credential registrations.
+const (
+	EventTypeAuthorizationServerRegistration = "wellknown.authorization.server.registration"
+	EventTypeOpenIDConfigurationRegistration = "wellknown.openid.configuration.registration"
+	EventTypeVerifierRegistration            = "wellknown.verifier.registration"
+	EventTypeJwtVcIssuerRegistration         = "wellknown.jwt.vc.issuer.registration"
+
+	// EventTypeIssuerDeregistration removes the issuer of a tenant together with its credential configurations
+	EventTypeIssuerDeregistration = "wellknown.issuer.deregistration"
+	// EventTypeIssuerCredentialDeregistration withdraws a single credential configuration
+	EventTypeIssuerCredentialDeregistration = "wellknown.issuer.credential.deregistration"
+)
+
+// Solicitation of registrations, published by the broadcast importer. Plugins answer with their regular
+// registration events on messaging.TopicIssuerRegistration.
+const (
+	TopicIssuerSolicitation     = "wellknown.issuer.solicitation"
+	EventTypeIssuerSolicitation = "wellknown.issuer.solicitation"
+)
+
+// Request/reply topic for verifier metadata, served analogous to messaging.TopicGetIssuerMetadata
+const (
+	TopicGetVerifierMetadata     = "wellknown.verifier.metadata"
+	EventTypeGetVerifierMetadata = "wellknown.verifier.metadata"
+)
+
+type AuthorizationServerRegistration struct {
+	common.Request
+	AuthorizationServer AuthorizationServerMetadata `json:"authorization_server"`
+}
+
+type OpenIDConfigurationRegistration struct {
+	common.Request
+	OpenIDConfiguration oauth.OpenIdConfiguration `json:"openid_configuration"`
+}
+
+type VerifierRegistration struct {
+	common.Request
+	Verifier VerifierMetadata `json:"verifier"`
+}
+
+type JwtVcIssuerRegistration struct {
+	common.Request
+	JwtVcIssuer JwtVcIssuerMetadata `json:"jwt_vc_issuer"`
+}
+
+type IssuerDeregistration struct {
+	common.Request
+}
+
+// CredentialDeregistration is the counterpart of messaging.CredentialRegistration and uses the same field names
+type CredentialDeregistration struct {
+	common.Request
+	ConfigurationId string `json:"ConfigurationId"`
+}
+
+// IssuerSolicitation asks the plugins of a tenant to publish their registrations again until ReplyUntil
+type IssuerSolicitation struct {
+	common.Request
+	ReplyUntil time.Time `json:"reply_until"`
+}
+
+type GetVerifierMetadataReq struct {
+	common.Request
+}
+
+type GetVerifierMetadataReply struct {
+	common.Reply
+	Verifier *VerifierMetadata `json:"verifier"`
+}
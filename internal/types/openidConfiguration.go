@@ -0,0 +1,22 @@
+package types
+
+import (
+	"github.com/eclipse-xfsc/oid4-vci-vp-library/model/oauth"
+)
+
+// DeriveOpenIDConfiguration builds the OpenID Connect discovery document of issuers without an explicitly
+// published one from their RFC 8414 Authorization Server Metadata. Only the values of the authorization server
+// are used, values without a counterpart (e.g. the userinfo endpoint) stay empty.
+func DeriveOpenIDConfiguration(metadata AuthorizationServerMetadata) *oauth.OpenIdConfiguration {
+	return &oauth.OpenIdConfiguration{
+		Issuer:                                metadata.Issuer,
+		Authorization_Endpoint:                metadata.AuthorizationEndpoint,
+		Token_Endpoint:                        metadata.TokenEndpoint,
+		Jwks_Uri:                              metadata.JwksUri,
+		Scopes_Supported:                      metadata.ScopesSupported,
+		Response_Types_Supported:              metadata.ResponseTypesSupported,
+		Grant_Types_Supported:                 metadata.GrantTypesSupported,
+		Token_Endpoint_Auth_Methods_Supported: metadata.TokenEndpointAuthMethodsSupported,
+		Token_Endpoint_Auth_Signing_Alg_Values_Supported: metadata.TokenEndpointAuthSigningAlgValuesSupported,
+	}
+}
@@ -1,6 +1,27 @@
 package types
 
+import "context"
+
+// TemplateData is passed to the templates of issuer.json and the credential configurations
 type TemplateData struct {
+	// Origin is the scheme and host of the request, e.g. https://example.org
 	Origin   string
 	TenantId string
+	// BaseURL is the URL of the tenant in front of /.well-known, e.g. https://example.org/v1/tenants/tenant-a
+	BaseURL string
+	// Credentials is the JSON object of the credential configurations, only set for issuer.json
+	Credentials string
+}
+
+type templateDataKey struct{}
+
+// WithTemplateData returns a context carrying the template data of a request
+func WithTemplateData(ctx context.Context, data TemplateData) context.Context {
+	return context.WithValue(ctx, templateDataKey{}, data)
+}
+
+// TemplateDataFromContext returns the template data of the request, if any
+func TemplateDataFromContext(ctx context.Context) (TemplateData, bool) {
+	data, ok := ctx.Value(templateDataKey{}).(TemplateData)
+	return data, ok
 }
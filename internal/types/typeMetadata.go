@@ -0,0 +1,224 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-library/model/credential"
+)
+
+// Keywords of a stored JSON schema which are used to describe the type hierarchy of a vct.
+// They are not part of JSON schema and ignored by validators.
+const (
+	SchemaKeywordExtends          = "x-vct-extends"
+	SchemaKeywordExtendsIntegrity = "x-vct-extends#integrity"
+)
+
+// keys of the claims description of a credential configuration which do not describe nested claims
+var claimDescriptionKeys = map[string]bool{
+	"display":    true,
+	"mandatory":  true,
+	"value_type": true,
+}
+
+// TypeMetadata represents the SD-JWT VC Type Metadata of a vct
+type TypeMetadata struct {
+	Vct                string                 `json:"vct"`
+	Name               string                 `json:"name,omitempty"`
+	Description        string                 `json:"description,omitempty"`
+	Extends            string                 `json:"extends,omitempty"`
+	ExtendsIntegrity   string                 `json:"extends#integrity,omitempty"`
+	Display            []TypeDisplay          `json:"display,omitempty"`
+	Claims             []ClaimMetadata        `json:"claims,omitempty"`
+	Schema             map[string]interface{} `json:"schema,omitempty"`
+	SchemaUri          string                 `json:"schema_uri,omitempty"`
+	SchemaUriIntegrity string                 `json:"schema_uri#integrity,omitempty"`
+}
+
+type TypeDisplay struct {
+	Lang        string     `json:"lang"`
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Rendering   *Rendering `json:"rendering,omitempty"`
+}
+
+type Rendering struct {
+	Simple *SimpleRendering `json:"simple,omitempty"`
+}
+
+type SimpleRendering struct {
+	Logo            *Logo  `json:"logo,omitempty"`
+	BackgroundColor string `json:"background_color,omitempty"`
+	TextColor       string `json:"text_color,omitempty"`
+}
+
+type Logo struct {
+	Uri     string `json:"uri"`
+	AltText string `json:"alt_text,omitempty"`
+}
+
+type ClaimMetadata struct {
+	Path    []string       `json:"path"`
+	Display []ClaimDisplay `json:"display,omitempty"`
+}
+
+type ClaimDisplay struct {
+	Lang  string `json:"lang"`
+	Label string `json:"label"`
+}
+
+// ErrAmbiguousReference is returned by FindConfigurationByVct, if a reference matches several configurations
+var ErrAmbiguousReference = errors.New("reference matches several credential configurations")
+
+// ErrUnknownReference is returned by FindConfigurationByVct, if no configuration matches a reference
+var ErrUnknownReference = errors.New("reference matches no credential configuration")
+
+// FindConfigurationByVct looks up the credential configuration which is identified by ref. The reference is
+// either the configuration id, the complete vct, the path of a vct URL or a trailing part of that path, tried
+// in this order. A reference which matches several configurations on the first matching level is ambiguous.
+func FindConfigurationByVct(configurations map[string]credential.CredentialConfiguration, ref string) (string, *credential.CredentialConfiguration, error) {
+	ref = strings.Trim(ref, "/")
+	if ref == "" {
+		return "", nil, ErrUnknownReference
+	}
+
+	if configuration, ok := configurations[ref]; ok && configuration.Vct != nil {
+		return ref, &configuration, nil
+	}
+
+	const (
+		vctMatch = iota
+		pathMatch
+		suffixMatch
+		levels
+	)
+
+	var matches [levels][]string
+	for id, configuration := range configurations {
+		if configuration.Vct == nil {
+			continue
+		}
+
+		if *configuration.Vct == ref {
+			matches[vctMatch] = append(matches[vctMatch], id)
+			continue
+		}
+
+		u, err := url.Parse(*configuration.Vct)
+		if err != nil || u.Path == "" {
+			continue
+		}
+
+		path := strings.Trim(u.Path, "/")
+		switch {
+		case path == ref:
+			matches[pathMatch] = append(matches[pathMatch], id)
+		case strings.HasSuffix(path, "/"+ref):
+			matches[suffixMatch] = append(matches[suffixMatch], id)
+		}
+	}
+
+	for _, ids := range matches {
+		switch len(ids) {
+		case 0:
+			continue
+		case 1:
+			configuration := configurations[ids[0]]
+			return ids[0], &configuration, nil
+		default:
+			sort.Strings(ids)
+			return "", nil, fmt.Errorf("%w: %s", ErrAmbiguousReference, strings.Join(ids, ", "))
+		}
+	}
+
+	return "", nil, ErrUnknownReference
+}
+
+// NewTypeMetadata generates the type metadata of a credential configuration from its display, claims and schema.
+// The schema is embedded; callers which publish it by reference have to replace it by schema_uri.
+func NewTypeMetadata(configuration credential.CredentialConfiguration) TypeMetadata {
+	metadata := TypeMetadata{
+		Schema: configuration.Schema,
+	}
+
+	if configuration.Vct != nil {
+		metadata.Vct = *configuration.Vct
+	}
+
+	for i, display := range configuration.Display {
+		if i == 0 {
+			metadata.Name = display.Name
+		}
+
+		td := TypeDisplay{Lang: display.Locale, Name: display.Name}
+
+		simple := SimpleRendering{
+			BackgroundColor: display.BackgroundColor,
+			TextColor:       display.TextColor,
+		}
+		if display.Logo.URL != "" {
+			simple.Logo = &Logo{Uri: display.Logo.URL, AltText: display.Logo.AlternativeText}
+		}
+		if simple != (SimpleRendering{}) {
+			td.Rendering = &Rendering{Simple: &simple}
+		}
+
+		metadata.Display = append(metadata.Display, td)
+	}
+
+	if extends, ok := configuration.Schema[SchemaKeywordExtends].(string); ok {
+		metadata.Extends = extends
+		metadata.ExtendsIntegrity, _ = configuration.Schema[SchemaKeywordExtendsIntegrity].(string)
+	}
+
+	metadata.Claims = collectClaimMetadata(nil, configuration.Claims)
+
+	return metadata
+}
+
+// collectClaimMetadata walks the (nested) claims description of OID4VCI and returns an entry for every
+// claim which carries display information
+func collectClaimMetadata(path []string, claims map[string]interface{}) []ClaimMetadata {
+	names := make([]string, 0, len(claims))
+	for name := range claims {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	out := make([]ClaimMetadata, 0)
+	for _, name := range names {
+		if len(path) > 0 && claimDescriptionKeys[name] {
+			continue
+		}
+
+		description, ok := claims[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		claimPath := append(append([]string{}, path...), name)
+
+		if displays, ok := description["display"].([]interface{}); ok {
+			claim := ClaimMetadata{Path: claimPath}
+			for _, d := range displays {
+				display, ok := d.(map[string]interface{})
+				if !ok {
+					continue
+				}
+
+				label, _ := display["name"].(string)
+				lang, _ := display["locale"].(string)
+				claim.Display = append(claim.Display, ClaimDisplay{Lang: lang, Label: label})
+			}
+
+			out = append(out, claim)
+		}
+
+		out = append(out, collectClaimMetadata(claimPath, description)...)
+	}
+
+	return out
+}
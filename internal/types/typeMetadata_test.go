@@ -0,0 +1,56 @@
+package types
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/eclipse-xfsc/oid4-vci-vp-library/model/credential"
+)
+
+func TestFindConfigurationByVct(t *testing.T) {
+	vct := func(v string) credential.CredentialConfiguration {
+		return credential.CredentialConfiguration{Vct: &v}
+	}
+
+	configurations := map[string]credential.CredentialConfiguration{
+		"pid":      vct("https://issuer.example/v1/tenants/t1/.well-known/vct/pid"),
+		"pid-old":  vct("https://issuer.example/v1/tenants/t2/.well-known/vct/pid"),
+		"urn":      vct("urn:eu.europa.ec.eudi:pid:1"),
+		"health":   vct("https://other.example/health"),
+		"health-2": vct("https://other.example/v2/health"),
+		"no-vct":   {},
+	}
+
+	tests := []struct {
+		name string
+		ref  string
+		id   string
+		err  error
+	}{
+		{name: "configuration id wins over suffix", ref: "pid", id: "pid"},
+		{name: "complete vct", ref: "urn:eu.europa.ec.eudi:pid:1", id: "urn"},
+		{name: "complete path", ref: "v1/tenants/t1/.well-known/vct/pid", id: "pid"},
+		{name: "unique suffix", ref: "t2/.well-known/vct/pid", id: "pid-old"},
+		{name: "path wins over suffix", ref: "health", id: "health"},
+		{name: "ambiguous suffix", ref: ".well-known/vct/pid", err: ErrAmbiguousReference},
+		{name: "configuration without vct", ref: "no-vct", err: ErrUnknownReference},
+		{name: "unknown", ref: "unknown", err: ErrUnknownReference},
+		{name: "empty", ref: "/", err: ErrUnknownReference},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, configuration, err := FindConfigurationByVct(configurations, tt.ref)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+			if tt.err != nil {
+				return
+			}
+
+			if id != tt.id || configuration == nil {
+				t.Fatalf("expected configuration %s, got %s", tt.id, id)
+			}
+		})
+	}
+}
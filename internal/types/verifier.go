@@ -0,0 +1,31 @@
+package types
+
+import "errors"
+
+// VerifierMetadata represents the OID4VP Verifier Metadata (client metadata) of a tenant
+type VerifierMetadata struct {
+	ClientID                            string                 `json:"client_id"`
+	ClientName                          string                 `json:"client_name,omitempty"`
+	LogoUri                             string                 `json:"logo_uri,omitempty"`
+	ClientIDSchemesSupported            []string               `json:"client_id_schemes_supported,omitempty"`
+	VpFormatsSupported                  map[string]interface{} `json:"vp_formats_supported"`
+	Jwks                                map[string]interface{} `json:"jwks,omitempty"`
+	JwksUri                             string                 `json:"jwks_uri,omitempty"`
+	AuthorizationSignedResponseAlg      string                 `json:"authorization_signed_response_alg,omitempty"`
+	AuthorizationEncryptedResponseAlg   string                 `json:"authorization_encrypted_response_alg,omitempty"`
+	AuthorizationEncryptedResponseEnc   string                 `json:"authorization_encrypted_response_enc,omitempty"`
+	EncryptedResponseEncValuesSupported []string               `json:"encrypted_response_enc_values_supported,omitempty"`
+}
+
+// Validate checks the fields which are required to store the verifier metadata
+func (m VerifierMetadata) Validate() error {
+	if m.ClientID == "" {
+		return errors.New("verifier metadata without client_id")
+	}
+
+	if len(m.VpFormatsSupported) == 0 {
+		return errors.New("verifier metadata without vp_formats_supported")
+	}
+
+	return nil
+}
@@ -4,9 +4,14 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"slices"
+	"strings"
 	"time"
 
+	core "github.com/eclipse-xfsc/crypto-provider-core"
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/auth"
 	postgresPkg "github.com/eclipse-xfsc/microservice-core-go/pkg/db/postgres"
 	errPkg "github.com/eclipse-xfsc/microservice-core-go/pkg/err"
 	"github.com/eclipse-xfsc/microservice-core-go/pkg/logr"
@@ -18,19 +23,36 @@ import (
 
 	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/config"
 	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/common"
+	pgAssets "github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/database/assets/postgres"
+	pgAuthServers "github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/database/authservers/postgres"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/database/issuers"
 	pgIssuers "github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/database/issuers/postgres"
 	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/database/postgres"
+	pgVerifiers "github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/database/verifiers/postgres"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/expiry"
 	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/gateway/nats"
 	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/gateway/rest"
 	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/importer"
 	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/importer/broadcast"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/importer/composite"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/importer/file"
 	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/importer/git"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/importer/mirror"
 	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/service"
+	"github.com/eclipse-xfsc/oid4-vci-vp-well-known-service/internal/signer"
 )
 
 var env *common.Environment
 var conf config.Config
 
+// @title						Well-Known Service API
+// @version					1.0
+// @description				Serves OID4VCI/OID4VP well-known metadata per tenant and manages it through the admin API.
+// @BasePath					/v1/tenants/{tenantId}
+// @securityDefinitions.apikey	BearerAuth
+// @in							header
+// @name						Authorization
+// @description				Bearer token verified against WELLKNOWN_SERVICE_ADMIN_JWKS_URL
 func main() {
 	errGrp, ctx := errgroup.WithContext(context.Background())
 
@@ -62,45 +84,151 @@ func main() {
 		os.Exit(1)
 	}
 
-	issuerSvc := service.NewIssuerService(pgIssuers.NewStore(pgDb, *logger, conf))
+	var metadataSigner *signer.Signer
+	if conf.Signing.Enabled {
+		provider := core.CreateCryptoEngine(conf.Signing.PluginPath)
+		if provider == nil {
+			provider = core.CryptoEngine()
+		}
+		metadataSigner = signer.New(conf.Signing, provider, *logger)
+	}
+
+	// the composite importer signs the merged metadata, signatures of the stored metadata would not cover it
+	storeSigner := metadataSigner
+	if conf.CredentialIssuer.Importer == config.ImporterComposite {
+		storeSigner = nil
+	}
+
+	issuerSvc := service.NewIssuerService(pgIssuers.NewStore(pgDb, *logger, conf), storeSigner)
+	authServerSvc := service.NewAuthorizationServerService(pgAuthServers.NewStore(pgDb, *logger))
+	verifierSvc := service.NewVerifierService(pgVerifiers.NewStore(pgDb, *logger))
+	assetSvc := service.NewAssetService(pgAssets.NewStore(pgDb, *logger))
+
+	newImporter := func(kind string, metadataSigner *signer.Signer) importer.Importer {
+		switch kind {
+		case config.ImporterGit:
+			gitConf := conf.Git
+			gitConf.SyncDatabase = conf.GitSyncsDatabase()
+			if gitConf.PublicURL == "" {
+				gitConf.PublicURL = conf.Gateway.PublicURL
+			}
+			return git.NewImporter(gitConf, issuerSvc.WithSource(issuers.SourceGit), authServerSvc, verifierSvc, metadataSigner, *logger)
+		case config.ImporterFile:
+			return file.NewImporter(conf.File, metadataSigner, *logger)
+		case config.ImporterMirror:
+			return mirror.NewImporter(issuerSvc.WithSource(issuers.SourceMirror), authServerSvc, verifierSvc, assetSvc, conf.Mirror, *logger)
+		case config.ImporterBroadcast:
+			return broadcast.NewImporter(issuerSvc, authServerSvc, verifierSvc, assetSvc, conf.Nats, conf.Solicitation, *logger)
+		default:
+			panic("no importer defined")
+		}
+	}
 
 	var imp importer.Importer
-	switch conf.CredentialIssuer.Importer {
-	case config.ImporterGit:
-		imp = git.NewImporter(conf.Git, *logger)
-	case config.ImporterBroadcast:
-		imp = broadcast.NewImporter(issuerSvc, conf.Nats, *logger)
-	default:
-		panic("no importer defined")
+	if conf.CredentialIssuer.Importer == config.ImporterComposite {
+		// the composite importer signs the merged metadata
+		importers := make(map[string]importer.Importer, len(conf.Composite.Importers))
+		for _, kind := range conf.Composite.Importers {
+			importers[kind] = newImporter(kind, nil)
+		}
+		imp = composite.NewImporter(conf.Composite, importers, metadataSigner, *logger)
+	} else {
+		imp = newImporter(conf.CredentialIssuer.Importer, metadataSigner)
 	}
 
 	env = common.GetEnvironment()
 	env.SetLogger(logger)
 	env.SetConfig(&conf)
-	env.SetHealthFunc(imp.GotErrors)
+	// the health func reports whether the service is healthy, an importer with errors is not
+	env.SetHealthFunc(func() bool {
+		return !imp.GotErrors()
+	})
 
 	logger.Debug("starting rest server")
 
 	server := serverPkg.New(env)
+	server.SetHealthHandler(rest.HealthHandler(env))
+
 	if err := imp.Start(ctx, server, env); err != nil {
 		logger.Error(err, "Importer cant be started")
 	}
 	defer imp.Stop()
 
-	restGW := rest.NewGateway(conf.Gateway, imp)
+	// configurations in the database are only refreshed by broadcasts
+	if slices.Contains(conf.Importers(), config.ImporterBroadcast) && conf.CredentialConfigurationSweepInterval > 0 {
+		sweeper := expiry.NewSweeper(
+			issuerSvc,
+			time.Duration(conf.CredentialConfigurationExpiration)*time.Second,
+			conf.CredentialConfigurationSweepInterval,
+			*logger,
+		)
+		if err := sweeper.Start(ctx); err != nil {
+			logger.Error(err, "expiry sweeper cant be started")
+		}
+		defer sweeper.Stop()
+
+		env.AddHealthDetail("expiry", func() any {
+			return sweeper.Status()
+		})
+	}
+
+	restGW := rest.NewGateway(conf.Gateway, imp, metadataSigner)
 
 	server.Add(func(rg *gin.RouterGroup) {
 		wk := rg.Group("/.well-known")
 		wk.GET("/openid-credential-issuer", restGW.WellKnownCredentialIssuerHandler)
+		wk.GET("/oauth-authorization-server", restGW.WellKnownAuthorizationServerHandler)
+		wk.GET("/openid-configuration", restGW.WellKnownOpenIDConfigurationHandler)
+		wk.GET("/openid-verifier", restGW.WellKnownVerifierHandler)
+		wk.GET("/jwt-vc-issuer", restGW.WellKnownJwtVcIssuerHandler)
+		wk.GET("/vct/*vct", restGW.WellKnownTypeMetadataHandler)
+		wk.GET("/vct-schema/:configurationId", restGW.WellKnownTypeSchemaHandler)
+
+		if metadataSigner != nil {
+			wk.GET("/jwks.json", restGW.WellKnownJwksHandler)
+		}
+
+		rg.GET(common.AssetsPath+"/*asset", restGW.AssetHandler)
+		rg.HEAD(common.AssetsPath+"/*asset", restGW.AssetHandler)
+
+		for _, imagePath := range conf.LegacyImagePaths() {
+			rg.GET(strings.TrimRight(imagePath, "/")+"/*file", restGW.LegacyImageHandler(imagePath))
+		}
 	})
 
+	if conf.Admin.Enabled {
+		authMiddleware, err := auth.NewMiddleware(conf.Admin.JwksURL, conf.Admin.JwksRefreshInterval, http.DefaultClient)
+		if err != nil {
+			logger.Error(err, "failed to create admin authentication")
+			os.Exit(1)
+		}
+
+		adminGW := rest.NewAdminGateway(conf.Admin, issuerSvc.WithSource(issuers.SourceAdmin), assetSvc)
+
+		server.Add(func(rg *gin.RouterGroup) {
+			admin := rg.Group("/admin", rest.Authenticate(authMiddleware), adminGW.AuthorizeTenant)
+			admin.GET("/issuer", adminGW.GetIssuerHandler)
+			admin.POST("/issuer", adminGW.CreateIssuerHandler)
+			admin.PUT("/issuer", adminGW.UpdateIssuerHandler)
+			admin.DELETE("/issuer", adminGW.DeleteIssuerHandler)
+			admin.GET("/configurations", adminGW.ListConfigurationsHandler)
+			admin.GET("/configurations/:configurationId", adminGW.GetConfigurationHandler)
+			admin.POST("/configurations/:configurationId", adminGW.CreateConfigurationHandler)
+			admin.PUT("/configurations/:configurationId", adminGW.UpdateConfigurationHandler)
+			admin.DELETE("/configurations/:configurationId", adminGW.DeleteConfigurationHandler)
+			admin.GET("/assets", adminGW.ListAssetsHandler)
+			admin.PUT("/assets/:name", adminGW.UploadAssetHandler)
+			admin.DELETE("/assets/:name", adminGW.DeleteAssetHandler)
+		})
+	}
+
 	errGrp.Go(func() error {
 		return server.Run(conf.ListenPort, conf.ListenAddr)
 	})
 
 	logger.Debug("starting nats listener")
 
-	natsGW := nats.NewGateway(issuerSvc, conf.Nats)
+	natsGW := nats.NewGateway(issuerSvc, verifierSvc, conf.Nats)
 	errGrp.Go(func() error {
 		return natsGW.Run(ctx)
 	})